@@ -0,0 +1,102 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// escape sequences (CSI, OSC and two character sequences), and the color (SGR) subset of them
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+var sgrRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripAnsi(text string) string {
+	if !strings.Contains(text, "\x1b") { return text }
+	return ansiRegex.ReplaceAllString(text, "")
+}
+
+// a line of colored input, and where each byte of its plain text is in the raw text
+type ansiLine struct {
+	raw string
+	plain string
+	rawIndices []int // has an extra entry for the end of the plain text
+}
+
+func newAnsiLine(raw string) (line ansiLine) {
+	line.raw = raw
+
+	var plain strings.Builder
+	rawIndex := 0
+	for _, sequence := range append(ansiRegex.FindAllStringIndex(raw, -1), []int{ len(raw), len(raw) }) {
+		// copy text before the sequence
+		for ; rawIndex < sequence[0]; rawIndex++ {
+			plain.WriteByte(raw[rawIndex])
+			line.rawIndices = append(line.rawIndices, rawIndex)
+		}
+		rawIndex = sequence[1]
+	}
+	line.rawIndices = append(line.rawIndices, len(raw))
+	line.plain = plain.String()
+
+	return
+}
+
+// gets the colored text of a range of the plain text
+// (colors set before the range are carried in, and non-color sequences are dropped)
+func (l ansiLine) getColoredRange(start, end int) string {
+	rawStart, rawEnd := l.rawIndices[start], l.rawIndices[end]
+
+	// only colors since the last reset matter
+	carriedSequences := sgrRegex.FindAllString(l.raw[:rawStart], -1)
+	for i := len(carriedSequences) - 1; i >= 0; i-- {
+		if carriedSequences[i] == "\x1b[0m" || carriedSequences[i] == "\x1b[m" {
+			carriedSequences = carriedSequences[i + 1:]
+			break
+		}
+	}
+	carriedColors := strings.Join(carriedSequences, "")
+	coloredText := ansiRegex.ReplaceAllStringFunc(l.raw[rawStart:rawEnd], func(sequence string) string {
+		if sgrRegex.MatchString(sequence) { return sequence }
+		return ""
+	})
+	return carriedColors + coloredText
+}
+
+// finds the colored text of each row's cells in the raw input lines
+// (cells are looked for in order in their row's line, cells that can't be found aren't colored)
+// rawLines start after lineOffset lines of the input
+func getColoredEntries(rawLines []string, lineOffset int, headers []string, table parsedTable) map[string][]string {
+	coloredEntriesByColumn := make(map[string][]string)
+	for _, header := range headers {
+		coloredEntriesByColumn[header] = make([]string, len(table.rows))
+	}
+
+	for r, row := range table.rows {
+		lineNumber := table.rowLines[r] - lineOffset
+		if lineNumber < 1 || lineNumber > len(rawLines) || !strings.Contains(rawLines[lineNumber - 1], "\x1b") { continue }
+
+		line := newAnsiLine(rawLines[lineNumber - 1])
+		searchStart := 0
+		for c, value := range row {
+			if value == "" || c >= len(headers) { continue }
+
+			index := strings.Index(line.plain[searchStart:], value)
+			if index == -1 { continue }
+
+			start := searchStart + index
+			searchStart = start + len(value)
+			if coloredValue := line.getColoredRange(start, searchStart); strings.Contains(coloredValue, "\x1b") {
+				coloredEntriesByColumn[headers[c]][r] = coloredValue
+			}
+		}
+	}
+
+	return coloredEntriesByColumn
+}
+
+// gets the colored text of an entry, or "" if it doesn't have colors
+func getColoredDataInColumn(header string, entry int) string {
+	if column, ok := data.coloredEntriesByColumn[header]; ok {
+		return column[entry]
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// starts a command, its output is read while it runs
+// (reading to the end waits for the command, and gives a commandError if it failed)
+func getCommandOutput(command string) io.Reader {
+	output := &commandOutput{ cmd: exec.Command("sh", "-c", command) }
+	output.cmd.Stderr = &output.stderr
+
+	stdout, err := output.cmd.StdoutPipe()
+	if err == nil { err = output.cmd.Start() }
+	if err != nil {
+		output.err = &commandError{ command, -1, err.Error() }
+		return output
+	}
+	output.stdout = stdout
+
+	return output
+}
+
+// output of a running command
+type commandOutput struct {
+	cmd *exec.Cmd
+	stdout io.Reader
+	stderr bytes.Buffer
+	err error // set when the command couldn't start, or once it has failed
+}
+
+func (o *commandOutput) Read(p []byte) (int, error) {
+	if o.err != nil { return 0, o.err }
+
+	n, err := o.stdout.Read(p)
+	if err == io.EOF {
+		if waitErr := o.cmd.Wait(); waitErr != nil {
+			exitCode := -1
+			var exitErr *exec.ExitError
+			if errors.As(waitErr, &exitErr) { exitCode = exitErr.ExitCode() }
+
+			o.err = &commandError{ strings.Join(o.cmd.Args[2:], " "), exitCode, strings.TrimSpace(o.stderr.String()) }
+			return n, o.err
+		}
+	}
+	return n, err
+}
+
+// a command that exited with a non-zero status (or couldn't run), and what it wrote to stderr
+type commandError struct {
+	command string
+	exitCode int
+	stderr string
+}
+
+func (e *commandError) Error() string {
+	message := fmt.Sprintf("Command (%v) failed with exit status %v", e.command, e.exitCode)
+	if e.stderr != "" {
+		// the last line is usually the reason
+		lines := strings.Split(e.stderr, "\n")
+		message += ": " + lines[len(lines) - 1]
+	}
+	return message
+}
+
+// gets the status to exit with because of the failure (never 0)
+func (e *commandError) getExitCode() int {
+	if e.exitCode > 0 {
+		return e.exitCode
+	}
+	return 1
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// comparison filters match entries by their typed values, like "> 3", "<= 1h", "1Gi..2Gi" or "in (a, b, c)"
+// values have to be of the column's type, only text columns are compared as text
+
+// operators, longest first so they are matched before their prefixes
+var comparisonOperators = []string{ ">=", "<=", "!=", "==", ">", "<" }
+
+// a parsed comparison filter
+type comparison struct {
+	operator string // one of comparisonOperators, ".." (inclusive range) or "in"
+	operands []string
+}
+
+func parseComparison(text string) (comparison, error) {
+	text = strings.TrimSpace(text)
+
+	// list
+	if rest, found := strings.CutPrefix(text, "in"); found {
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+			return comparison{}, fmt.Errorf("list must be in parentheses, like in (a, b)")
+		}
+
+		var operands []string
+		for _, operand := range strings.Split(rest[1:len(rest) - 1], ",") {
+			operands = append(operands, trimComparisonOperand(operand))
+		}
+		return comparison{ "in", operands }, nil
+	}
+
+	// operator and value
+	for _, operator := range comparisonOperators {
+		if rest, found := strings.CutPrefix(text, operator); found {
+			operand := trimComparisonOperand(rest)
+			if operand == "" { return comparison{}, fmt.Errorf("missing value after %v", operator) }
+			return comparison{ operator, []string{ operand } }, nil
+		}
+	}
+
+	// range
+	if low, high, found := strings.Cut(text, ".."); found {
+		low, high = trimComparisonOperand(low), trimComparisonOperand(high)
+		if low == "" || high == "" { return comparison{}, fmt.Errorf("range needs a low and a high value, like 1..5") }
+		return comparison{ "..", []string{ low, high } }, nil
+	}
+
+	return comparison{}, fmt.Errorf("expected an operator (%v), a range (a..b) or a list (in (a, b))", strings.Join(comparisonOperators, " "))
+}
+
+// trims spaces and quotes around a value
+func trimComparisonOperand(operand string) string {
+	operand = strings.TrimSpace(operand)
+	if len(operand) >= 2 && (operand[0] == '"' || operand[0] == '\'') && operand[len(operand) - 1] == operand[0] {
+		operand = operand[1:len(operand) - 1]
+	}
+	return operand
+}
+
+// parses a value to compare to like the column's values (whole numbers can be compared with decimals, and versions with a major version)
+func parseComparisonOperand(operand string, columnType string) typedValue {
+	parsed := parseTypedValue(operand, columnType)
+	if parsed == nil && columnType == "int" { parsed = parseTypedValue(operand, "float") }
+	if parsed == nil && columnType == "version" { parsed = parseTypedValue(operand + ".0", "version") }
+	return parsed
+}
+
+// checks that the values of a comparison are of the column's type
+// (text columns take any value, missing values like - can be checked for equality, and columns that aren't loaded yet aren't checked)
+func validateComparisonOperands(c comparison, header string) error {
+	if _, found := data.entriesByColumn[header]; !found { return nil }
+
+	columnType := getColumnType(header)
+	if columnType == "text" { return nil }
+	for _, operand := range c.operands {
+		isEquality := c.operator == "==" || c.operator == "!=" || c.operator == "in"
+		if isEquality && isMissingValue(operand) { continue }
+		if parseComparisonOperand(operand, columnType) == nil {
+			return fmt.Errorf("%q is not a %v (the type of column %v)", operand, columnType, header)
+		}
+	}
+	return nil
+}
+
+// gets a test for entries whose value in a column passes the comparison
+func getComparisonTest(c comparison, header string) func(entryIndex int) bool {
+	entries := data.entriesByColumn[header]
+	typedValues := getTypedColumn(header)
+	columnType := getColumnType(header)
+
+	typedOperands := make([]typedValue, len(c.operands))
+	for i, operand := range c.operands {
+		typedOperands[i] = parseComparisonOperand(operand, columnType)
+	}
+
+	// compares an entry to an operand, ok is false when a missing value can't be ordered
+	compareTo := func(entryIndex int, operand int) (result int, ok bool) {
+		if typedOperands[operand] != nil && typedValues[entryIndex] != nil {
+			return compareTypedValues(typedValues[entryIndex], typedOperands[operand]), true
+		}
+		if typedOperands[operand] != nil || isMissingValue(entries[entryIndex]) {
+			return 0, false
+		}
+		return strings.Compare(entries[entryIndex], c.operands[operand]), true
+	}
+	isEqualTo := func(entryIndex int, operand int) bool {
+		if result, ok := compareTo(entryIndex, operand); ok {
+			return result == 0
+		}
+		return entries[entryIndex] == c.operands[operand]
+	}
+
+	return func(entryIndex int) bool {
+		switch c.operator {
+		case "in":
+			for operand := range c.operands {
+				if isEqualTo(entryIndex, operand) { return true }
+			}
+			return false
+		case "==":
+			return isEqualTo(entryIndex, 0)
+		case "!=":
+			return !isEqualTo(entryIndex, 0)
+		case "..":
+			low, lowOk := compareTo(entryIndex, 0)
+			high, highOk := compareTo(entryIndex, 1)
+			return lowOk && highOk && low >= 0 && high <= 0
+		}
+
+		result, ok := compareTo(entryIndex, 0)
+		if !ok { return false }
+		switch c.operator {
+		case ">":
+			return result > 0
+		case ">=":
+			return result >= 0
+		case "<":
+			return result < 0
+		default:
+			return result <= 0
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestComparison(t *testing.T) {
+	loadInputs([]inputSource{ { "test", strings.NewReader("name age\na 5m\nb 12m\nc 45m\nd 2h\ne 30s\n") } }, false)
+
+	tests := []struct {
+		filter string
+		names []string
+	}{
+		{ "< 1h", []string{ "a", "b", "c", "e" } },
+		{ ">= 45m", []string{ "c", "d" } },
+		{ "10m..1h", []string{ "b", "c" } },
+		{ "in (5m, 2h)", []string{ "a", "d" } },
+		{ "!= 30s", []string{ "a", "b", "c", "d" } },
+	}
+	for _, test := range tests {
+		parsed, err := parseComparison(test.filter)
+		if err == nil { err = validateComparisonOperands(parsed, "age") }
+		if err != nil {
+			t.Errorf("%v: %v", test.filter, err)
+			continue
+		}
+
+		var names []string
+		passes := getComparisonTest(parsed, "age")
+		for entry := range data.numEntries {
+			if passes(entry) { names = append(names, data.entriesByColumn["name"][entry]) }
+		}
+		if !slices.Equal(names, test.names) {
+			t.Errorf("%v matched %v, want %v", test.filter, names, test.names)
+		}
+	}
+
+	// values that aren't of the column's type are refused
+	parsed, _ := parseComparison("< 1x")
+	if validateComparisonOperands(parsed, "age") == nil {
+		t.Error("< 1x was accepted for a duration column")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// filter fields suggest values from their column, the most common first, narrowed to the ones containing what's typed.
+// picking a value adds it to the field: patterns become an alternation (a|b) and lists become in (a, b)
+// (type the separator, | or a comma, to pick another)
+
+// how many values are suggested
+const maxCompletions = 20
+
+// a field's text, split around the value being typed
+type valueCompletion struct {
+	partial string // the value being typed
+	picked []string // values already in the text (as they are written in it)
+	format func(value string) string // how a value is written in the text
+	complete func(value string) string // the text with the value being typed replaced by a value
+}
+
+// gets the distinct values of a column, the most common first
+func getRankedValues(header string) []string {
+	counts := make(map[string]int)
+	for _, entry := range data.entriesByColumn[header] {
+		if strings.TrimSpace(entry) != "" { counts[entry]++ }
+	}
+
+	values := make([]string, 0, len(counts))
+	for value := range counts { values = append(values, value) }
+	slices.SortFunc(values, func(a, b string) int {
+		if counts[a] != counts[b] { return counts[b] - counts[a] }
+		return cmp.Compare(a, b)
+	})
+	return values
+}
+
+// gets the values to suggest (without the ones already picked)
+func getValueCompletions(values []string, completion *valueCompletion) []string {
+	partial := strings.ToLower(completion.partial)
+
+	var completions []string
+	for _, value := range values {
+		if len(completions) >= maxCompletions { break }
+		if !strings.Contains(strings.ToLower(value), partial) || slices.Contains(completion.picked, completion.format(value)) { continue }
+		completions = append(completions, value)
+	}
+	return completions
+}
+
+// completes include and exclude patterns (only regexes can have more than one value)
+func getPatternCompletion(text string, options FilterOptions) *valueCompletion {
+	if options.Syntax != "" {
+		identity := func(value string) string { return value }
+		return &valueCompletion{ text, nil, identity, identity }
+	}
+
+	terms := strings.Split(text, "|")
+	picked := terms[:len(terms) - 1]
+	return &valueCompletion{
+		terms[len(terms) - 1],
+		picked,
+		regexp.QuoteMeta,
+		func(value string) string { return strings.Join(append(slices.Clone(picked), regexp.QuoteMeta(value)), "|") },
+	}
+}
+
+// completes the value of a comparison, or the values of a list (nil when there's no operator yet)
+func getComparisonCompletion(text string) *valueCompletion {
+	text = strings.TrimLeft(text, " ")
+	identity := func(value string) string { return value }
+
+	// list
+	if rest, found := strings.CutPrefix(text, "in"); found && (strings.TrimSpace(rest) == "" || strings.HasPrefix(strings.TrimSpace(rest), "(")) {
+		body := strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(rest), "("), ")", "")
+		terms := strings.Split(body, ",")
+		var picked []string
+		for _, term := range terms[:len(terms) - 1] {
+			if term = trimComparisonOperand(term); term != "" { picked = append(picked, term) }
+		}
+		return &valueCompletion{
+			strings.TrimSpace(terms[len(terms) - 1]),
+			picked,
+			identity,
+			func(value string) string { return "in (" + strings.Join(append(slices.Clone(picked), value), ", ") + ")" },
+		}
+	}
+
+	// operator and value
+	for _, operator := range comparisonOperators {
+		if rest, found := strings.CutPrefix(text, operator); found {
+			return &valueCompletion{
+				strings.TrimSpace(rest),
+				nil,
+				identity,
+				func(value string) string { return operator + " " + value },
+			}
+		}
+	}
+	return nil
+}
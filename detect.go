@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// how many lines/records are looked at when sniffing the input
+const detectSampleSize = 20
+
+// guesses the parse mode that matches the input
+func detectParseMode(inputString string) string {
+	trimmed := strings.TrimSpace(inputString)
+	if trimmed == "" {
+		return "positional"
+	}
+
+	// json documents and streams
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		if json.Valid([]byte(trimmed)) {
+			return "json"
+		}
+		if isJSONLines(trimmed) {
+			return "jsonl"
+		}
+	}
+
+	lines := getSampleLines(inputString)
+
+	// text split by the given delimiter (before boxes, so a | delimiter isn't taken for a markdown table)
+	if *flags.delimiter != "" {
+		delimiter := getDelimiter("csv")
+		if strings.ContainsRune(lines[0], delimiter) && isDelimited(inputString, delimiter) {
+			if delimiter == '\t' { return "tsv" }
+			return "csv"
+		}
+	}
+
+	// tables drawn with borders (psql, mysql, sqlite3 -box, markdown)
+	if isBoxTable(lines) {
+		return "box"
+	}
+
+	// delimited text (the header must contain the delimiter)
+	if strings.Contains(lines[0], "\t") && isDelimited(inputString, '\t') {
+		return "tsv"
+	}
+	if strings.Contains(lines[0], ",") && isDelimited(inputString, ',') {
+		return "csv"
+	}
+
+	// fixed width text, unless the columns clearly don't line up
+	if !isAligned(lines) && hasConsistentFieldCount(lines) {
+		return "whitespace"
+	}
+	return "positional"
+}
+
+// gets the first few non-empty lines of the input
+func getSampleLines(inputString string) (lines []string) {
+	for _, line := range strings.Split(inputString, "\n") {
+		if strings.TrimSpace(line) == "" { continue }
+
+		lines = append(lines, strings.TrimRight(line, "\r"))
+		if len(lines) == detectSampleSize { break }
+	}
+	return
+}
+
+func isJSONLines(inputString string) bool {
+	for _, line := range getSampleLines(inputString) {
+		if !json.Valid([]byte(line)) {
+			return false
+		}
+	}
+	return true
+}
+
+// checks for a bordered top line, or a divided header with a separator line under it
+func isBoxTable(lines []string) bool {
+	firstLine := strings.TrimSpace(lines[0])
+	if isBoxSeparator(firstLine) {
+		// top border needs a corner (so underlined headers aren't mistaken for a border)
+		return strings.ContainsAny(firstLine, "+┌┏╔╒╓╭")
+	}
+
+	separatorLine := max(1, getNumHeaderLines())
+	return len(lines) > separatorLine && strings.ContainsAny(firstLine, boxDividers) && isBoxSeparator(strings.TrimSpace(lines[separatorLine]))
+}
+
+// checks that the first few records split into the same number (> 1) of fields
+func isDelimited(inputString string, delimiter rune) bool {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(inputString, "\ufeff")))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = 0
+
+	for i := 0; i < detectSampleSize; i++ {
+		record, err := reader.Read()
+		if err != nil {
+			// running out of input is fine as long as there was a header and a row
+			return err == io.EOF && i > 1
+		}
+		if len(record) < 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// checks that the lines have gaps that line up into columns
+func isAligned(lines []string) bool {
+	// (only under the header, since the last column can run past it)
+	masks := getDisplayMasks(lines)
+	overlay := overlayLines(masks)[:len(masks[0])]
+	return len(strings.Fields(masks[0])) < 2 || len(strings.Fields(overlay)) > 1
+}
+
+func hasConsistentFieldCount(lines []string) bool {
+	numFields := len(strings.Fields(lines[0]))
+	for _, line := range lines[1:] {
+		if len(strings.Fields(line)) != numFields {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// filter expressions test whole entries, like: status == Error or restarts > 5
+// they combine conditions on columns (by name) with and, or, not and parentheses.
+// conditions are regex matches (~ and !~), comparisons (see compare.go) and lists (in (a, b))
+// values with spaces or operator characters can be quoted
+
+// comparisons, regex matches and = (which is ==, and comes after it since it's a prefix of it)
+var expressionOperators = append(slices.Clone(comparisonOperators), "!~", "~", "=")
+
+type expressionToken struct {
+	text string
+	quoted bool
+	position int
+}
+
+// a parsed expression ("and", "or" and "not" nodes have children, other nodes are conditions on a column)
+type expressionNode struct {
+	operator string
+	children []*expressionNode
+	column string
+	operands []string
+	regex *regexp.Regexp
+}
+
+func parseFilterExpression(text string) (*expressionNode, error) {
+	tokens, err := tokenizeExpression(text)
+	if err != nil { return nil, err }
+	if len(tokens) == 0 { return nil, fmt.Errorf("expression is empty") }
+
+	parser := expressionParser{ tokens, 0, len(text) }
+	node, err := parser.parseOr()
+	if err != nil { return nil, err }
+	if parser.index < len(tokens) {
+		return nil, parser.errorf("unexpected %q", tokens[parser.index].text)
+	}
+	return node, nil
+}
+
+func tokenizeExpression(text string) ([]expressionToken, error) {
+	var tokens []expressionToken
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == ' ' || text[i] == '\t' || text[i] == '\n':
+			i++
+		case strings.ContainsRune("(),", rune(text[i])):
+			tokens = append(tokens, expressionToken{ text[i:i + 1], false, i })
+			i++
+		case text[i] == '"' || text[i] == '\'':
+			// quoted (a quote inside is escaped with a backslash)
+			quote := text[i]
+			var value strings.Builder
+			end := i + 1
+			for ; end < len(text) && text[end] != quote; end++ {
+				if text[end] == '\\' && end + 1 < len(text) && text[end + 1] == quote { end++ }
+				value.WriteByte(text[end])
+			}
+			if end >= len(text) { return nil, fmt.Errorf("unclosed quote at position %v", i + 1) }
+			tokens = append(tokens, expressionToken{ value.String(), true, i })
+			i = end + 1
+		default:
+			// operator, or a word
+			operatorIndex := slices.IndexFunc(expressionOperators, func(operator string) bool { return strings.HasPrefix(text[i:], operator) })
+			if operatorIndex != -1 {
+				tokens = append(tokens, expressionToken{ expressionOperators[operatorIndex], false, i })
+				i += len(expressionOperators[operatorIndex])
+				continue
+			}
+
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\n(),\"'<>=!~", rune(text[end])) { end++ }
+			tokens = append(tokens, expressionToken{ text[i:end], false, i })
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type expressionParser struct {
+	tokens []expressionToken
+	index int
+	textLength int
+}
+
+func (p *expressionParser) errorf(format string, args ...any) error {
+	position := p.textLength
+	if p.index < len(p.tokens) { position = p.tokens[p.index].position }
+	return fmt.Errorf("%v at position %v", fmt.Sprintf(format, args...), position + 1)
+}
+
+// checks if the next token is a keyword (or punctuation), and moves past it if it is
+func (p *expressionParser) accept(keyword string) bool {
+	if p.index >= len(p.tokens) || p.tokens[p.index].quoted || !strings.EqualFold(p.tokens[p.index].text, keyword) {
+		return false
+	}
+	p.index++
+	return true
+}
+
+// gets the next token as a value (a word or quoted text)
+func (p *expressionParser) value(expected string) (string, error) {
+	if p.index >= len(p.tokens) { return "", p.errorf("expected %v", expected) }
+
+	token := p.tokens[p.index]
+	if !token.quoted && (slices.Contains([]string{ "(", ")", "," }, token.text) || slices.Contains(expressionOperators, token.text)) {
+		return "", p.errorf("expected %v, found %q", expected, token.text)
+	}
+	p.index++
+	return token.text, nil
+}
+
+func (p *expressionParser) parseOr() (*expressionNode, error) {
+	node, err := p.parseAnd()
+	if err != nil { return nil, err }
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil { return nil, err }
+		node = &expressionNode{ operator: "or", children: []*expressionNode{ node, right } }
+	}
+	return node, nil
+}
+
+func (p *expressionParser) parseAnd() (*expressionNode, error) {
+	node, err := p.parseNot()
+	if err != nil { return nil, err }
+	for p.accept("and") {
+		right, err := p.parseNot()
+		if err != nil { return nil, err }
+		node = &expressionNode{ operator: "and", children: []*expressionNode{ node, right } }
+	}
+	return node, nil
+}
+
+func (p *expressionParser) parseNot() (*expressionNode, error) {
+	if p.accept("not") {
+		child, err := p.parseNot()
+		if err != nil { return nil, err }
+		return &expressionNode{ operator: "not", children: []*expressionNode{ child } }, nil
+	}
+
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil { return nil, err }
+		if !p.accept(")") { return nil, p.errorf("expected )") }
+		return node, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *expressionParser) parseCondition() (*expressionNode, error) {
+	column, err := p.value("a column")
+	if err != nil { return nil, err }
+
+	// list
+	if p.accept("in") {
+		if !p.accept("(") { return nil, p.errorf("expected ( after in") }
+		var operands []string
+		for {
+			operand, err := p.value("a value")
+			if err != nil { return nil, err }
+			operands = append(operands, operand)
+			if p.accept(")") { break }
+			if !p.accept(",") { return nil, p.errorf("expected , or )") }
+		}
+		return &expressionNode{ operator: "in", column: column, operands: operands }, nil
+	}
+
+	// operator and value
+	if p.index >= len(p.tokens) || p.tokens[p.index].quoted || !slices.Contains(expressionOperators, p.tokens[p.index].text) {
+		return nil, p.errorf("expected an operator (%v or in) after column %q", strings.Join(expressionOperators, " "), column)
+	}
+	operator := p.tokens[p.index].text
+	if operator == "=" { operator = "==" }
+	p.index++
+
+	operand, err := p.value("a value")
+	if err != nil { return nil, err }
+	node := &expressionNode{ operator: operator, column: column, operands: []string{ operand } }
+
+	if operator == "~" || operator == "!~" {
+		node.regex, err = regexp.Compile(operand)
+		if err != nil {
+			p.index--
+			return nil, p.errorf("invalid regex: %v", err)
+		}
+	}
+	return node, nil
+}
+
+// finds the header a column in an expression refers to (case is ignored, unless headers only differ by case)
+func resolveExpressionColumn(column string) (string, bool) {
+	if slices.Contains(data.columnHeaders, column) { return column, true }
+
+	index := slices.IndexFunc(data.columnHeaders, func(header string) bool { return strings.EqualFold(header, column) })
+	if index == -1 { return "", false }
+	return data.columnHeaders[index], true
+}
+
+// gets the columns of an expression that aren't in the data
+func getUnknownExpressionColumns(node *expressionNode) []string {
+	var unknown []string
+	if node.column != "" {
+		if _, ok := resolveExpressionColumn(node.column); !ok { unknown = append(unknown, node.column) }
+	}
+	for _, child := range node.children {
+		unknown = append(unknown, getUnknownExpressionColumns(child)...)
+	}
+	return unknown
+}
+
+// checks that the values compared to columns are of the columns' types (see validateComparisonOperands)
+func validateExpressionOperands(node *expressionNode) error {
+	if node.column != "" && node.regex == nil {
+		if header, ok := resolveExpressionColumn(node.column); ok {
+			if err := validateComparisonOperands(comparison{ node.operator, node.operands }, header); err != nil { return err }
+		}
+	}
+	for _, child := range node.children {
+		if err := validateExpressionOperands(child); err != nil { return err }
+	}
+	return nil
+}
+
+// gets a test for entries that pass an expression (conditions on columns that aren't in the data never pass)
+func getExpressionTest(node *expressionNode) func(entryIndex int) bool {
+	switch node.operator {
+	case "and", "or":
+		left, right := getExpressionTest(node.children[0]), getExpressionTest(node.children[1])
+		if node.operator == "and" {
+			return func(entryIndex int) bool { return left(entryIndex) && right(entryIndex) }
+		}
+		return func(entryIndex int) bool { return left(entryIndex) || right(entryIndex) }
+	case "not":
+		child := getExpressionTest(node.children[0])
+		return func(entryIndex int) bool { return !child(entryIndex) }
+	}
+
+	header, ok := resolveExpressionColumn(node.column)
+	if !ok { return func(entryIndex int) bool { return false } }
+
+	if node.regex != nil {
+		entries, isMatch := data.entriesByColumn[header], node.operator == "~"
+		return func(entryIndex int) bool { return node.regex.MatchString(entries[entryIndex]) == isMatch }
+	}
+	return getComparisonTest(comparison{ node.operator, node.operands }, header)
+}
@@ -46,6 +46,9 @@ var instructionCategories map[string]InstructionCategory = map[string]Instructio
 			"[::b]C-y[::-] - open column menu.",
 			"[::b]q[::-] - quit.",
 			"[::b]p[::-] - quit and print.",
+			"[::b]F[::-] - filter rows with an expression.",
+			"[::b]a[::-] - toggle auto scroll to new entries.",
+			"[::b]r[::-] - refresh (re-run the command).",
 		},
 	},
 	"column" : {
@@ -54,7 +57,10 @@ var instructionCategories map[string]InstructionCategory = map[string]Instructio
 		instructions: []string{
 			"[::b]x[::-] - delete column.",
 			"[::b]s[::-] - sort by column.",
+			"[::b]S[::-] - add column to sort (then flip, then remove).",
+			"[::b]o[::-] - change sort mode (typed, natural, nocase, text).",
 			"[::b]f[::-] - filter column.",
+			"[::b]t[::-] - change column type.",
 			"[::b]C-q[::-] - move column left.",
 			"[::b]C-e[::-] - move column right.",
 		},
@@ -3,11 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
-	"os/exec"
-	"strings"
+	"regexp"
+	"slices"
+	"time"
+	"unicode/utf8"
 )
 
 // flags
@@ -16,6 +17,23 @@ var flags = struct {
 	loadPath *string
 	preset *string
 	parseMode *string
+	delimiter *string
+	headers *string
+	columnWidths *string
+	headerRows *int
+	noHeader *bool
+	skipLines *int
+	headerRegex *string
+	stopRegex *string
+	dropRepeatedHeaders *bool
+	raggedRows *string
+	keepColors *bool
+	sourceColumn *bool
+	follow *bool
+	maxRows *int
+	watch *time.Duration
+	keyColumn *string
+	partialOutput *bool
 	fatTable *bool
 	stdout *bool
 	noFluff *bool
@@ -25,7 +43,24 @@ var flags = struct {
 	flag.String("command", "", "Command used to fetch table."),
 	flag.String("load", "", "Path to load transformation from."),
 	flag.String("p", "", "Name of preset to load"),
-	flag.String("parseMode", "positional", "Table parsing mode. 'whitespace' or 'positional' are accepted."),
+	flag.String("parseMode", "auto", "Table parsing mode. 'auto', 'whitespace', 'positional', 'csv', 'tsv', 'json', 'jsonl' or 'box' are accepted. 'auto' detects the mode from the input."),
+	flag.String("delimiter", "", "Field delimiter for the 'csv' and 'tsv' parse modes. Defaults to ',' for csv and tab for tsv. 'auto' tries it when given."),
+	flag.String("headers", "", "Comma separated header names to look for in the header line (positional mode). Overrides inferred columns."),
+	flag.String("columnWidths", "", "Comma separated column widths (positional mode). The last column runs until the end of the line. Overrides inferred columns."),
+	flag.Int("headerRows", 1, "Number of lines that make up the header. Multi-line headers are merged into one name per column."),
+	flag.Bool("noHeader", false, "Enable when the input has no header line. Columns are named col1..colN (or named by -headers)."),
+	flag.Int("skipLines", 0, "Number of preamble lines to skip before the header. Saved with the transformation."),
+	flag.String("headerRegex", "", "Regex that finds the header line, everything before it is skipped. Saved with the transformation."),
+	flag.String("stopRegex", "", "Regex that finds the line where the table ends (like a summary), it and everything after it are dropped. Saved with the transformation."),
+	flag.Bool("dropRepeatedHeaders", false, "Enable to drop lines that repeat the header. Saved with the transformation."),
+	flag.String("raggedRows", "fold", "What to do with rows that have too few or too many cells. 'fold' (pad missing cells, fold extra cells into the last column), 'pad' (pad missing cells, drop extra cells), 'drop' (drop the row) or 'error' (exit, reporting the line) are accepted."),
+	flag.Bool("keepColors", false, "Enable to keep the colors of colored input in the TUI and in fluffed stdout output. Colors are always stripped for parsing and filtering."),
+	flag.Bool("sourceColumn", false, "Enable to add a 'source' column with the input (file, stdin or command) each entry came from."),
+	flag.Bool("follow", false, "Enable for input that keeps growing (like tail -f). The last input file is read as it is appended to, and the TUI scrolls to new entries."),
+	flag.Int("maxRows", 0, "Maximum number of entries to keep, the oldest are dropped as new ones come in. 0 keeps every entry."),
+	flag.Duration("watch", 0, "Interval to re-run the command at (like '5s'), updating the TUI. The command can also be re-run with the refresh key."),
+	flag.String("keyColumn", "", "Column that identifies entries when the command is re-run, so the selection stays on the same entry. Defaults to the first column."),
+	flag.Bool("partialOutput", false, "Enable to still show what a failed command output. Its exit status and stderr are reported either way."),
 	flag.Bool("fatTable", false, "Table display mode. Enable to turn on table borders."),
 	flag.Bool("stdout", false, "Print output instead of displaying TUI. This will effectively do nothing if you don't load a transformation as well."),
 	flag.Bool("noFluff", false, "Enable to disable fluff (colors and symbols) in the stdout output."),
@@ -39,23 +74,87 @@ var data = struct {
 	columnHeaders []string
 	numEntries int
 	numHeaderRows int
+	parseMode string
+	headerRows [][]string
+	parseWarning string
+	coloredEntriesByColumn map[string][]string
+	loading bool
+	commandError *commandError
+	columnTypes map[string]string // inferred
+	typedColumns map[string]*typedColumn
+	sortedEntries *sortedEntries
 }{
 	nil,
 	nil,
 	0,
 	1,
+	"",
+	nil,
+	"",
+	nil,
+	false,
+	nil,
+	nil,
+	nil,
+	nil,
 }
 
 func main() {
 
 	// parse flags
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [flags] [files...]\nFiles are read instead of stdin or -command, '-' reads stdin.\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	// initialize config
+	initializeConfig()
+
+	// load transformation (before validating flags and finding input, since it can hold the command and parsing options)
+	initializeTransformation()
+
 	// validate flags
-	if *flags.parseMode != "positional" && *flags.parseMode != "whitespace" {
+	if !slices.Contains(parseModes, *flags.parseMode) {
 		fmt.Println("Bad parse mode")
 		os.Exit(1)
 	}
+	if !slices.Contains(raggedRowPolicies, *flags.raggedRows) {
+		fmt.Println("Bad ragged rows policy")
+		os.Exit(1)
+	}
+	if utf8.RuneCountInString(*flags.delimiter) > 1 && *flags.delimiter != `\t` {
+		fmt.Println("Bad delimiter, it must be a single character")
+		os.Exit(1)
+	}
+	if *flags.headerRows < 1 {
+		fmt.Println("Bad header rows, there must be at least one (use -noHeader for headerless input)")
+		os.Exit(1)
+	}
+	if *flags.columnWidths != "" {
+		if _, err := parseColumnWidths(*flags.columnWidths); err != nil {
+			fmt.Printf("Bad column widths: %v\n", err)
+			os.Exit(1)
+		}
+		if *flags.headers != "" {
+			fmt.Println("Only one of -headers and -columnWidths can be used")
+			os.Exit(1)
+		}
+	}
+	if *flags.skipLines < 0 {
+		fmt.Println("Bad skip lines, it can't be negative")
+		os.Exit(1)
+	}
+	if *flags.maxRows < 0 {
+		fmt.Println("Bad max rows, it can't be negative")
+		os.Exit(1)
+	}
+	for _, pattern := range []string{ *flags.headerRegex, *flags.stopRegex } {
+		if _, err := regexp.Compile(pattern); err != nil {
+			fmt.Printf("Bad regex: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	// detect std out
     if fi, _ := os.Stdout.Stat(); (fi.Mode() & os.ModeCharDevice) == 0 {
 		*flags.stdout = true
@@ -64,112 +163,79 @@ func main() {
 	if *flags.forceTui {
 		*flags.stdout = false
 	}
+	if *flags.follow && *flags.stdout {
+		fmt.Println("Follow mode needs the TUI (use -forceTui when piping)")
+		os.Exit(1)
+	}
+	if *flags.watch != 0 && (*flags.watch < 0 || *flags.stdout) {
+		fmt.Println("Bad watch interval, it must be positive and needs the TUI (use -forceTui when piping)")
+		os.Exit(1)
+	}
 
-	// initialize config
-	initializeConfig()
+	// get input from files, stdin or running command
+	var inputSources []inputSource
+	if flag.NArg() > 0 {
+		// read input from files
+		for _, path := range flag.Args() {
+			inputSources = append(inputSources, openInputFile(path))
+		}
 
-	// get input from stdin or running command
-	var inputText string
-    if fi, _ := os.Stdin.Stat(); (fi.Mode() & os.ModeCharDevice) == 0 {
-		// read input from std in
-		bytes, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Println("Failed to read stdin")
-			os.Exit(1)
+		// follow the last file as it is appended to
+		if last := &inputSources[len(inputSources) - 1]; *flags.follow && last.reader != os.Stdin {
+			last.reader = &followReader{ last.reader.(*os.File) }
 		}
-		inputText = string(bytes)
+	} else if fi, _ := os.Stdin.Stat(); (fi.Mode() & os.ModeCharDevice) == 0 {
+		// read input from std in
+		inputSources = append(inputSources, openInputFile("-"))
 	} else if *flags.command != "" {
 		// read input from command
-		inputText = getCommandOutput(*flags.command)
+		inputSources = append(inputSources, inputSource{ *flags.command, getCommandOutput(*flags.command) })
+		refreshableCommand = *flags.command
 	} else {
-		log.Fatal("Could not find an input source. Please use the -command flag or stdin.")
+		log.Fatal("Could not find an input source. Please give files, or use the -command flag, stdin or a preset with a command.")
+	}
+	if *flags.watch > 0 && refreshableCommand == "" {
+		log.Fatal("Watch mode needs input from the -command flag, since it re-runs the command.")
 	}
 
-	// parse text into input entries
-	parseInput(inputText)
-
-	// load or generate default transformation
-	initializeTransformation()
-
-	// generate output
-	transformDataToOutput()
-
-	// run TUI
+	// run TUI (input is loaded while it runs)
 	if !*flags.stdout {
-		setupTui()
-	} else {
-		printTable()
+		setupTui(inputSources)
+		return
 	}
-}
 
-func parseInput(inputString string) {
-	data.entriesByColumn = make(map[string][]string) 
-
-	lines := strings.Split(inputString, "\n")
-	data.columnHeaders = strings.Fields(lines[0])
-
-	// calculate header start indices (if positional)
-	var headerStartIndices []int = make([]int, len(data.columnHeaders))
-	if *flags.parseMode == "positional" {
-		lastHeaderEnd := 0
-		for i, header := range data.columnHeaders {
-			if i == 0 {
-				headerStartIndices[i] = 0
-				lastHeaderEnd = len(header)
-				continue
-			}
-
-			// search for next header index after last header
-			searchString := lines[0][lastHeaderEnd:]
-			headerStartIndices[i] = strings.Index(searchString, header) + lastHeaderEnd
-			lastHeaderEnd = headerStartIndices[i] + len(header)
-		}
+	// parse all of the input into entries, then generate output
+	if err := loadInputs(inputSources, false); err != nil {
+		log.Fatal(err)
+	}
+	if data.parseWarning != "" {
+		fmt.Fprintln(os.Stderr, data.parseWarning)
+	}
+	for _, filterError := range getFilterErrors() {
+		fmt.Fprintln(os.Stderr, filterError)
 	}
 
-	// iterate over each entry in the table
-	data.numEntries = 0
-	for i := 1; i < len(lines); i++ {
-		// skip over empty line (the last one) (also skips the entry count from increasing)
-		if (len(lines[i]) == 0) { continue }
-
-		// parse column entries (depending on mode)
-		if *flags.parseMode == "positional" {
-			// positional mode
-			// go through each position
-			for vindex, position := range headerStartIndices {
-				var value string
-				if (vindex < len(headerStartIndices) - 1) {
-					// normally we can go from the current position to the next
-					value = lines[i][position:headerStartIndices[vindex + 1]]
-				} else {
-					// if we are on the last header index, go until the end
-					value = lines[i][position:]
-				}
-				data.entriesByColumn[data.columnHeaders[vindex]] = append(data.entriesByColumn[data.columnHeaders[vindex]], strings.TrimSpace(value))
-			}
-
-		} else {
-			values := strings.Fields(lines[i])
-			// whitespace mode
-			// iterate through each value in the row and add it to the list
-			for vindex, value := range values {
-				data.entriesByColumn[data.columnHeaders[vindex]] = append(data.entriesByColumn[data.columnHeaders[vindex]], value)
-			}
-		}
-
-		// increment entry count
-		data.numEntries++
+	// a failed command fails the program (after printing what it output, if asked for)
+	if data.commandError != nil {
+		if data.commandError.stderr != "" { fmt.Fprintln(os.Stderr, data.commandError.stderr) }
+		fmt.Fprintln(os.Stderr, data.commandError.Error())
+		if !*flags.partialOutput { os.Exit(data.commandError.getExitCode()) }
 	}
+
+	transformDataToOutput()
+	printTable()
+	if data.commandError != nil { os.Exit(data.commandError.getExitCode()) }
 }
 
-func getCommandOutput(command string) string {
-	// get command output
-	cmd := exec.Command("sh", "-c", command)
-	stdout, err := cmd.Output()
-	if err != nil {
-		fmt.Println(err.Error())
-		return ""
+// opens an input file ("-" is stdin)
+func openInputFile(path string) inputSource {
+	if path == "-" {
+		return inputSource{ "stdin", os.Stdin }
 	}
 
-	return string(stdout)
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Could not read input file (%v): %v", path, err)
+	}
+	return inputSource{ path, file }
 }
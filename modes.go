@@ -83,6 +83,24 @@ func selectionModeInputCapture(event *tcell.EventKey) *tcell.EventKey {
 			printTable()
 			return nil
 		}
+		if event.Rune() == 'r' {
+			if refreshableCommand == "" {
+				writeToMessageBuffer("Only input from the -command flag can be refreshed")
+			} else {
+				refreshInput()
+			}
+			return nil
+		}
+		if event.Rune() == 'F' {
+			openExpressionFilterMenu()
+			return nil
+		}
+		if event.Rune() == 'a' {
+			autoScroll = !autoScroll
+			if autoScroll { scrollToLastRow() }
+			updateInfoText()
+			return nil
+		}
 	}
 
 	if event.Modifiers()&tcell.ModCtrl != 0 {
@@ -148,10 +166,22 @@ func (m ColumnMode) inputCapture(event *tcell.EventKey) *tcell.EventKey {
 			sortColumn()
 			return nil
 		}
+		if event.Rune() == 'S' {
+			addSortColumn()
+			return nil
+		}
 		if event.Rune() == 'f' {
 			openColumnFilterMenu()
 			return nil
 		}
+		if event.Rune() == 't' {
+			cycleColumnType()
+			return nil
+		}
+		if event.Rune() == 'o' {
+			cycleSortMode()
+			return nil
+		}
 	}
 	if event.Modifiers()&tcell.ModCtrl != 0 {
 		switch event.Key() {
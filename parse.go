@@ -0,0 +1,853 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	// external
+	"github.com/mattn/go-runewidth"
+)
+
+// all accepted values of the parseMode flag
+var parseModes = []string{ "auto", "positional", "whitespace", "csv", "tsv", "json", "jsonl", "box" }
+
+// all accepted values of the raggedRows flag
+var raggedRowPolicies = []string{ "fold", "pad", "drop", "error" }
+
+// parsed input before it is put into columns
+type parsedTable struct {
+	mode string
+	headerRows [][]string
+	rows [][]string
+	rowLines []int // line number of each row, for reporting malformed rows
+	numMalformed, firstMalformedLine int
+
+	// how the input was cut up, so lines streamed in after it are cut the same way
+	headerLines []string
+	columnStarts []int // positional
+	hasDividers bool // box
+	stopped bool // the stop regex matched
+}
+
+func (t *parsedTable) addRow(row []string, line int) {
+	t.rows = append(t.rows, row)
+	t.rowLines = append(t.rowLines, line)
+}
+
+// parses one input into headers and rows (and colors, if they are kept)
+func parseInput(inputString string) (headers []string, table parsedTable, coloredEntriesByColumn map[string][]string) {
+	// escape sequences are stripped for parsing (colors can be found again later)
+	rawInputString := inputString
+	inputString = stripAnsi(inputString)
+
+	// remove lines around the table
+	inputString, lineOffset, stopped := trimInputLines(inputString)
+
+	mode := resolveParseMode(inputString)
+
+	// parse into header rows and rows (depending on mode)
+	switch mode {
+	case "csv", "tsv":
+		table = parseDelimited(inputString, getDelimiter(mode))
+	case "json", "jsonl":
+		table = parseJSON(inputString, mode == "jsonl")
+	case "box":
+		table = parseBox(inputString)
+	case "whitespace":
+		table = parseWhitespace(inputString)
+	default:
+		table = parsePositional(inputString)
+	}
+	for i := range table.rowLines { table.rowLines[i] += lineOffset }
+
+	// keep what is needed to parse lines that stream in after this input
+	table.mode, table.stopped = mode, stopped
+	lines := strings.Split(inputString, "\n")
+	table.headerLines = lines[:min(getNumHeaderLines(), len(lines))]
+
+	headers = getHeaderNames(table.headerRows, table.rows)
+	fixRaggedRows(&table, len(headers))
+
+	// find the original colors of entries
+	if *flags.keepColors && strings.Contains(rawInputString, "\x1b") {
+		coloredEntriesByColumn = getColoredEntries(strings.Split(rawInputString, "\n"), 0, headers, table)
+	}
+
+	return
+}
+
+// gets the parse mode of an input (with the lines around the table removed), detecting it unless it's given
+func resolveParseMode(inputString string) string {
+	mode := *flags.parseMode
+	if mode == "auto" {
+		if *flags.headers != "" || *flags.columnWidths != "" {
+			mode = "positional"
+		} else {
+			mode = detectParseMode(inputString)
+		}
+	}
+	return mode
+}
+
+// skips preamble lines, stops at the terminator and drops repeated headers
+// returns the number of lines skipped before the table, and if the table was stopped
+func trimInputLines(inputString string) (string, int, bool) {
+	lines := strings.Split(inputString, "\n")
+
+	// skip preamble
+	lineOffset := min(transformation.SkipLines, len(lines))
+	lines = lines[lineOffset:]
+	if transformation.HeaderRegex != "" {
+		headerRegex := compileInputRegex(transformation.HeaderRegex)
+		headerIndex := slices.IndexFunc(lines, headerRegex.MatchString)
+		if headerIndex == -1 {
+			failInput("Could not find a header line matching the header regex (%v).", transformation.HeaderRegex)
+		}
+		lineOffset += headerIndex
+		lines = lines[headerIndex:]
+	}
+
+	// stop at terminator (which is never the header)
+	stopped := false
+	if transformation.StopRegex != "" {
+		stopRegex := compileInputRegex(transformation.StopRegex)
+		if stopIndex := slices.IndexFunc(lines[min(1, len(lines)):], stopRegex.MatchString); stopIndex != -1 {
+			lines = lines[:stopIndex + 1]
+			stopped = true
+		}
+	}
+
+	// drop lines that repeat the header (blanked, so line numbers stay the same)
+	if transformation.DropRepeatedHeaders {
+		numHeaderLines := min(getNumHeaderLines(), len(lines))
+		for i := numHeaderLines; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "" { continue }
+
+			isHeaderLine := slices.ContainsFunc(lines[:numHeaderLines], func(headerLine string) bool {
+				return strings.TrimSpace(headerLine) == strings.TrimSpace(lines[i])
+			})
+			if isHeaderLine { lines[i] = "" }
+		}
+	}
+
+	return strings.Join(lines, "\n"), lineOffset, stopped
+}
+
+func compileInputRegex(pattern string) *regexp.Regexp {
+	compiledRegex, err := regexp.Compile(pattern)
+	if err != nil {
+		failInput("Could not compile input regex (%v): %v", pattern, err)
+	}
+	return compiledRegex
+}
+
+// gets how many lines of the input make up the header
+func getNumHeaderLines() int {
+	if *flags.noHeader {
+		return 0
+	}
+	return *flags.headerRows
+}
+
+// merges header rows into one name per column, or generates names for headerless input
+func getHeaderNames(headerRows [][]string, rows [][]string) (headers []string) {
+	// headerless input gets names for as many columns as the widest row (unless they were given)
+	if len(headerRows) == 0 {
+		var givenHeaders []string
+		if *flags.headers != "" { givenHeaders = strings.Split(*flags.headers, ",") }
+
+		numColumns := 0
+		for _, row := range rows { numColumns = max(numColumns, len(row)) }
+		for i := 0; i < numColumns; i++ {
+			if i < len(givenHeaders) {
+				headers = append(headers, strings.TrimSpace(givenHeaders[i]))
+			} else {
+				headers = append(headers, fmt.Sprintf("col%v", i + 1))
+			}
+		}
+		return uniqueHeaders(headers)
+	}
+
+	// join the non-empty parts of each column
+	numColumns := 0
+	for _, headerRow := range headerRows { numColumns = max(numColumns, len(headerRow)) }
+	for c := 0; c < numColumns; c++ {
+		var parts []string
+		for _, headerRow := range headerRows {
+			if c < len(headerRow) && headerRow[c] != "" { parts = append(parts, headerRow[c]) }
+		}
+		headers = append(headers, strings.Join(parts, " "))
+	}
+	return uniqueHeaders(headers)
+}
+
+// names empty headers and numbers duplicates, since each column needs its own name
+func uniqueHeaders(headers []string) []string {
+	seenHeaders := make(map[string]bool)
+	for i, header := range headers {
+		if header == "" { header = fmt.Sprintf("col%v", i + 1) }
+
+		uniqueHeader := header
+		for n := 2; seenHeaders[uniqueHeader]; n++ {
+			uniqueHeader = fmt.Sprintf("%v (%v)", header, n)
+		}
+
+		seenHeaders[uniqueHeader] = true
+		headers[i] = uniqueHeader
+	}
+	return headers
+}
+
+// applies the ragged rows policy to rows that don't have exactly one cell per column
+func fixRaggedRows(table *parsedTable, numColumns int) {
+	policy := *flags.raggedRows
+	numMalformed, firstMalformedLine := 0, 0
+
+	// overflow is folded back together with the separator it was split on
+	foldSeparator := " "
+	if table.mode == "csv" || table.mode == "tsv" { foldSeparator = string(getDelimiter(table.mode)) }
+
+	var rows [][]string
+	var rowLines []int
+	for i, row := range table.rows {
+		line := table.rowLines[i]
+		if len(row) != numColumns {
+			if policy == "error" {
+				failInput("Malformed row on line %v: expected %v cells but found %v.", line, numColumns, len(row))
+			}
+
+			if numMalformed == 0 { firstMalformedLine = line }
+			numMalformed++
+
+			switch {
+			case policy == "drop":
+				continue
+			case len(row) < numColumns:
+				// pad missing cells
+				row = append(row, make([]string, numColumns - len(row))...)
+			case policy == "fold" && numColumns > 0:
+				// fold the overflow into the last column
+				row = append(row[:numColumns - 1], strings.Join(row[numColumns - 1:], foldSeparator))
+			default:
+				// drop the overflow
+				row = row[:numColumns]
+			}
+		}
+
+		rows = append(rows, row)
+		rowLines = append(rowLines, line)
+	}
+	table.rows, table.rowLines = rows, rowLines
+	table.numMalformed, table.firstMalformedLine = numMalformed, firstMalformedLine
+}
+
+// describes the rows fixed by the ragged rows policy, or "" if there were none
+func getRaggedRowsWarning(numMalformed int, firstMalformedLine int) string {
+	switch {
+	case numMalformed == 0:
+		return ""
+	case *flags.raggedRows == "drop":
+		return fmt.Sprintf("%v malformed rows were dropped (first on line %v)", numMalformed, firstMalformedLine)
+	default:
+		return fmt.Sprintf("%v malformed rows were fixed with the '%v' policy (first on line %v)", numMalformed, *flags.raggedRows, firstMalformedLine)
+	}
+}
+
+// adds parsed rows to the input data
+func appendInputData(headers []string, rows [][]string, coloredEntriesByColumn map[string][]string) {
+	oldNumEntries := data.numEntries
+
+	// types are inferred again until there are enough entries to infer them from
+	if oldNumEntries < typeSampleSize { data.columnTypes = nil }
+
+	// add new columns (with no data for earlier entries)
+	for _, header := range headers {
+		if _, ok := data.entriesByColumn[header]; ok { continue }
+
+		data.columnHeaders = append(data.columnHeaders, header)
+		data.entriesByColumn[header] = slices.Repeat([]string{ noDataText }, oldNumEntries)
+	}
+
+	// iterate through each value in each row and add it to its column
+	for _, row := range rows {
+		for vindex, value := range row {
+			data.entriesByColumn[headers[vindex]] = append(data.entriesByColumn[headers[vindex]], value)
+		}
+	}
+	data.numEntries += len(rows)
+
+	// fill columns that these rows don't have
+	for _, header := range data.columnHeaders {
+		for len(data.entriesByColumn[header]) < data.numEntries {
+			data.entriesByColumn[header] = append(data.entriesByColumn[header], noDataText)
+		}
+	}
+
+	// add colors (entries without colors are empty)
+	if coloredEntriesByColumn != nil && data.coloredEntriesByColumn == nil {
+		data.coloredEntriesByColumn = make(map[string][]string)
+	}
+	if data.coloredEntriesByColumn != nil {
+		for _, header := range data.columnHeaders {
+			coloredColumn := data.coloredEntriesByColumn[header]
+			coloredColumn = append(coloredColumn, make([]string, oldNumEntries - len(coloredColumn))...)
+			coloredColumn = append(coloredColumn, coloredEntriesByColumn[header]...)
+			coloredColumn = append(coloredColumn, make([]string, data.numEntries - len(coloredColumn))...)
+			data.coloredEntriesByColumn[header] = coloredColumn
+		}
+	}
+}
+
+// drops the oldest entries from the input data, returns how many were dropped
+func dropOldestEntries(count int) int {
+	count = min(count, data.numEntries)
+	if count <= 0 { return 0 }
+
+	// (columns are copied, so the dropped entries can be freed)
+	for header, column := range data.entriesByColumn {
+		data.entriesByColumn[header] = slices.Clone(column[count:])
+	}
+	for header, coloredColumn := range data.coloredEntriesByColumn {
+		data.coloredEntriesByColumn[header] = slices.Clone(coloredColumn[count:])
+	}
+	data.numEntries -= count
+	data.typedColumns, data.sortedEntries = nil, nil
+
+	return count
+}
+
+// POSITIONAL / WHITESPACE ===================================================================
+
+// (rows are cut at the column positions, so they always have a cell for every column)
+func parsePositional(inputString string) (table parsedTable) {
+	lines := strings.Split(inputString, "\n")
+
+	// split header lines from data lines (skip over empty lines)
+	numHeaderLines := min(getNumHeaderLines(), len(lines))
+	headerLines := lines[:numHeaderLines]
+	var dataLines []string
+	var dataLineNumbers []int
+	for i := numHeaderLines; i < len(lines); i++ {
+		if len(lines[i]) == 0 { continue }
+
+		dataLines = append(dataLines, lines[i])
+		dataLineNumbers = append(dataLineNumbers, i + 1)
+	}
+
+	// columns are found in display columns (so wide characters line up), using masks of the lines
+	dataMasks := getDisplayMasks(dataLines)
+
+	// multi-line headers are overlaid into one line, and headerless input uses the shape of the data
+	headerMask := overlayLines(getDisplayMasks(headerLines))
+	if numHeaderLines == 0 { headerMask = overlayLines(dataMasks) }
+
+	// find columns (manually specified or inferred from alignment)
+	var headerStartIndices []int
+	switch {
+	case *flags.columnWidths != "":
+		widths, _ := parseColumnWidths(*flags.columnWidths)
+		headerStartIndices = getColumnsFromWidths(headerMask, widths)
+	case *flags.headers != "" && numHeaderLines > 0:
+		headerLine := overlayLines(headerLines)
+		headers := strings.Split(*flags.headers, ",")
+		for i := range headers { headers[i] = strings.TrimSpace(headers[i]) }
+		var err error
+		headerStartIndices, err = getHeaderStartIndices(headerLine, headers)
+		if err != nil { failInput("%v", err) }
+		for i, start := range headerStartIndices {
+			headerStartIndices[i] = runewidth.StringWidth(headerLine[:start])
+		}
+		widenColumnsToGaps(append([]string{ headerMask }, dataMasks...), headerStartIndices)
+		table.headerRows = [][]string{ headers }
+	default:
+		headerStartIndices = inferPositionalColumns(headerMask, dataMasks)
+	}
+
+	// cut header lines and entries into columns
+	if table.headerRows == nil {
+		for _, line := range headerLines {
+			table.headerRows = append(table.headerRows, sliceColumns(line, headerStartIndices))
+		}
+	}
+	for i, line := range dataLines {
+		table.addRow(sliceColumns(line, headerStartIndices), dataLineNumbers[i])
+	}
+	table.columnStarts = headerStartIndices
+
+	return
+}
+
+// cuts a line into trimmed values at each column start (in display columns)
+func sliceColumns(line string, headerStartIndices []int) []string {
+	// find the byte index where each column starts (wide characters belong to the column they start in)
+	byteStartIndices := make([]int, len(headerStartIndices))
+	next, displayColumn := 0, 0
+	for byteIndex, r := range line {
+		for next < len(headerStartIndices) && displayColumn >= headerStartIndices[next] {
+			byteStartIndices[next] = byteIndex
+			next++
+		}
+		displayColumn += getRuneDisplayWidth(r)
+	}
+	for ; next < len(byteStartIndices); next++ {
+		byteStartIndices[next] = len(line)
+	}
+
+	row := make([]string, len(headerStartIndices))
+	for vindex, start := range byteStartIndices {
+		end := len(line)
+		if (vindex < len(byteStartIndices) - 1) {
+			// normally we can go from the current position to the next
+			end = byteStartIndices[vindex + 1]
+		}
+		// if we are on the last header index, go until the end
+		row[vindex] = strings.TrimSpace(line[start:end])
+	}
+	return row
+}
+
+// tabs count as a single column, like they do in the display masks
+func getRuneDisplayWidth(r rune) int {
+	if r == '\t' {
+		return 1
+	}
+	return runewidth.RuneWidth(r)
+}
+
+// gets masks of lines with one byte per display column (' ' for spaces and 'x' for everything else),
+// so column positions can be found with byte offsets
+func getDisplayMasks(lines []string) []string {
+	masks := make([]string, len(lines))
+	for i, line := range lines {
+		var mask strings.Builder
+		for _, r := range line {
+			if r == ' ' || r == '\t' {
+				mask.WriteByte(' ')
+			} else {
+				mask.WriteString(strings.Repeat("x", getRuneDisplayWidth(r)))
+			}
+		}
+		masks[i] = mask.String()
+	}
+	return masks
+}
+
+// overlays lines on top of each other, the first non-space character at each position is kept
+func overlayLines(lines []string) string {
+	var overlay []byte
+	for _, line := range lines {
+		for i := 0; i < len(line); i++ {
+			if i >= len(overlay) { overlay = append(overlay, ' ') }
+			if overlay[i] == ' ' { overlay[i] = line[i] }
+		}
+	}
+	return string(overlay)
+}
+
+// calculates where each header starts in the header line
+func getHeaderStartIndices(headerLine string, headers []string) ([]int, error) {
+	var headerStartIndices []int = make([]int, len(headers))
+	lastHeaderEnd := 0
+	for i, header := range headers {
+		// search for next header index after last header
+		index := strings.Index(headerLine[lastHeaderEnd:], header)
+		if index == -1 {
+			if i == 0 { return nil, fmt.Errorf("Could not find header (%v) in the header line.", header) }
+			return nil, fmt.Errorf("Could not find header (%v) after header (%v) in the header line.", header, headers[i - 1])
+		}
+		headerStartIndices[i] = index + lastHeaderEnd
+		lastHeaderEnd = headerStartIndices[i] + len(header)
+	}
+	return headerStartIndices, nil
+}
+
+// gets where each word of a line starts
+func getWordStartIndices(line string) (wordStartIndices []int) {
+	for i := range line {
+		if line[i] != ' ' && (i == 0 || line[i - 1] == ' ') {
+			wordStartIndices = append(wordStartIndices, i)
+		}
+	}
+	return
+}
+
+// finds the columns of positional text
+// words in the header line separated by a single space are one header (like "CONTAINER ID"),
+// unless the data lines have a gap before the word and data under it
+func inferPositionalColumns(headerLine string, dataLines []string) (headerStartIndices []int) {
+	wordStartIndices := getWordStartIndices(headerLine)
+
+	// (the first column starts at the start of the line)
+	for i, start := range wordStartIndices {
+		if i == 0 {
+			headerStartIndices = append(headerStartIndices, 0)
+			continue
+		}
+		if isHeaderContinuation(headerLine, dataLines, wordStartIndices, i) { continue }
+		headerStartIndices = append(headerStartIndices, start)
+	}
+
+	widenColumnsToGaps(append([]string{ headerLine }, dataLines...), headerStartIndices)
+	return
+}
+
+// right aligned data can start before its header, so columns start after the closest gap
+// (the first column starts at the start of the line if there's no gap before it)
+func widenColumnsToGaps(lines []string, headerStartIndices []int) {
+	for i := range headerStartIndices {
+		previousStart := -1
+		if i > 0 { previousStart = headerStartIndices[i - 1] }
+
+		for position := headerStartIndices[i] - 1; position > previousStart; position-- {
+			if isGapInLines(lines, position) {
+				headerStartIndices[i] = position + 1
+				break
+			}
+			if position == 0 { headerStartIndices[i] = 0 }
+		}
+	}
+}
+
+// checks if every line has a space (or nothing) at a position
+func isGapInLines(lines []string, position int) bool {
+	for _, line := range lines {
+		if position < len(line) && line[position] != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// checks if a header word continues the header before it
+func isHeaderContinuation(headerLine string, dataLines []string, wordStartIndices []int, i int) bool {
+	start := wordStartIndices[i]
+	if len(dataLines) == 0 || start < 2 || headerLine[start - 2] == ' ' { return false }
+
+	end := -1
+	if i < len(wordStartIndices) - 1 { end = wordStartIndices[i + 1] }
+
+	// look for a gap before the word, and data under it
+	hasGap, hasData := true, false
+	for _, line := range dataLines {
+		if start - 1 < len(line) && line[start - 1] != ' ' { hasGap = false }
+		if start < len(line) {
+			lineEnd := len(line)
+			if end != -1 && end < lineEnd { lineEnd = end }
+			if strings.TrimSpace(line[start:lineEnd]) != "" { hasData = true }
+		}
+	}
+
+	return !hasGap || !hasData
+}
+
+// parses the columnWidths flag
+func parseColumnWidths(widthsString string) (widths []int, err error) {
+	for _, widthString := range strings.Split(widthsString, ",") {
+		width, err := strconv.Atoi(strings.TrimSpace(widthString))
+		if err != nil { return nil, err }
+		if width <= 0 { return nil, fmt.Errorf("width (%v) must be positive", width) }
+
+		widths = append(widths, width)
+	}
+	return
+}
+
+// gets columns from manually specified widths, the last column runs until the end of the line
+func getColumnsFromWidths(headerLine string, widths []int) (headerStartIndices []int) {
+	start := 0
+	for _, width := range widths {
+		headerStartIndices = append(headerStartIndices, start)
+		start += width
+	}
+	if start < len(headerLine) {
+		headerStartIndices = append(headerStartIndices, start)
+	}
+
+	return
+}
+
+func parseWhitespace(inputString string) (table parsedTable) {
+	lines := strings.Split(inputString, "\n")
+
+	numHeaderLines := min(getNumHeaderLines(), len(lines))
+	for _, line := range lines[:numHeaderLines] {
+		table.headerRows = append(table.headerRows, strings.Fields(line))
+	}
+
+	for i := numHeaderLines; i < len(lines); i++ {
+		// skip over empty lines (the last one)
+		if (len(strings.TrimSpace(lines[i])) == 0) { continue }
+
+		table.addRow(strings.Fields(lines[i]), i + 1)
+	}
+
+	return
+}
+
+// CSV / TSV =================================================================================
+
+// gets the delimiter from the flags, falling back on the parse mode's default
+func getDelimiter(mode string) rune {
+	delimiter := *flags.delimiter
+	if delimiter == "" {
+		if mode == "tsv" {
+			return '\t'
+		}
+		return ','
+	}
+
+	// allow escaped tab, since it is awkward to type on the command line
+	if delimiter == `\t` {
+		return '\t'
+	}
+
+	r, _ := utf8.DecodeRuneInString(delimiter)
+	return r
+}
+
+// parses RFC 4180 style input (quoted fields, escaped quotes, multi-line fields)
+func parseDelimited(inputString string, delimiter rune) (table parsedTable) {
+	// spreadsheet exports often start with a byte order mark
+	inputString = strings.TrimPrefix(inputString, "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(inputString))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1 // ragged records are handled by the ragged rows policy
+
+	numHeaderLines := getNumHeaderLines()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			failInput("Could not parse delimited input: %v", err)
+		}
+
+		if len(table.headerRows) < numHeaderLines {
+			for i := range record {
+				record[i] = strings.TrimSpace(record[i])
+			}
+			table.headerRows = append(table.headerRows, record)
+		} else {
+			line, _ := reader.FieldPos(0)
+			table.addRow(record, line)
+		}
+	}
+
+	return
+}
+
+// JSON / JSONL ==============================================================================
+
+// header used for records that are not objects (e.g. an array of strings)
+const jsonValueHeader = "value"
+
+// parses an array of objects (json) or a stream of objects (jsonl) into rows
+// nested objects are flattened into dotted paths, and the union of keys becomes the headers
+// (keys are always the header, so the header flags don't apply)
+func parseJSON(inputString string, lines bool) (table parsedTable) {
+	// gather records
+	var records []json.RawMessage
+	decoder := json.NewDecoder(strings.NewReader(inputString))
+	for {
+		var record json.RawMessage
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			failInput("Could not parse json input: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	// a single json document holds the records inside of it
+	if !lines && len(records) == 1 {
+		records = unwrapJSONRecords(records[0])
+	}
+
+	// flatten each record, keeping the order in which keys are first seen
+	var headers []string
+	var values []map[string]string
+	seenHeaders := make(map[string]bool)
+	for _, record := range records {
+		var keys []string
+		value := make(map[string]string)
+		if err := flattenJSON(record, "", value, &keys); err != nil {
+			failInput("Could not parse json input: %v", err)
+		}
+
+		for _, key := range keys {
+			if !seenHeaders[key] {
+				seenHeaders[key] = true
+				headers = append(headers, key)
+			}
+		}
+		values = append(values, value)
+	}
+
+	// build rows (missing keys have no data)
+	for _, value := range values {
+		row := make([]string, len(headers))
+		for i, header := range headers {
+			if entry, ok := value[header]; ok {
+				row[i] = entry
+			} else {
+				row[i] = noDataText
+			}
+		}
+		table.addRow(row, 0)
+	}
+
+	table.headerRows = [][]string{ headers }
+	return
+}
+
+// finds the list of records in a json document
+// arrays are a list of records, and list objects (like kubectl's) keep them in "items"
+func unwrapJSONRecords(document json.RawMessage) []json.RawMessage {
+	var records []json.RawMessage
+	if err := json.Unmarshal(document, &records); err == nil {
+		return records
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(document, &object); err == nil {
+		if items, ok := object["items"]; ok && json.Unmarshal(items, &records) == nil {
+			return records
+		}
+	}
+
+	return []json.RawMessage{ document }
+}
+
+// flattens a json value into path -> text, recording keys in the order they appear
+func flattenJSON(raw json.RawMessage, path string, out map[string]string, keys *[]string) error {
+	raw = bytes.TrimSpace(raw)
+
+	// leaf value
+	if len(raw) == 0 || raw[0] != '{' {
+		if path == "" { path = jsonValueHeader }
+		out[path] = jsonLeafText(raw)
+		*keys = append(*keys, path)
+		return nil
+	}
+
+	// object, walk keys in order
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil { return err }
+	if !decoder.More() && path != "" {
+		// keep empty nested objects visible
+		out[path] = "{}"
+		*keys = append(*keys, path)
+	}
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil { return err }
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil { return err }
+
+		key := keyToken.(string)
+		if path != "" { key = path + "." + key }
+		if err := flattenJSON(value, key, out, keys); err != nil { return err }
+	}
+
+	return nil
+}
+
+// gets the display text of a non-object json value
+func jsonLeafText(raw json.RawMessage) string {
+	switch {
+	case string(raw) == "null":
+		return ""
+	case len(raw) > 0 && raw[0] == '"':
+		var text string
+		json.Unmarshal(raw, &text)
+		return text
+	default:
+		// numbers, bools and arrays are shown as compact json
+		var compacted bytes.Buffer
+		if json.Compact(&compacted, raw) != nil {
+			return string(raw)
+		}
+		return compacted.String()
+	}
+}
+
+// BOX / PIPE ================================================================================
+
+// characters that split cells, and characters that can make up a separator line
+const boxDividers = "|│┃║"
+const boxSeparatorRunes = "|+-=:│┃║─━═┌┐└┘├┤┬┴┼┏┓┗┛┣┫┳┻╋╔╗╚╝╠╣╦╩╬╒╕╘╛╞╡╤╧╪╓╖╙╜╟╢╥╨╫╭╮╯╰ "
+
+// psql prints the row count after the table
+var psqlFooterRegex = regexp.MustCompile(`^\(\d+ rows?\)$`)
+
+// parses tables drawn with pipes or box drawing characters (psql, mysql, sqlite3 -box, markdown)
+// separator lines are dropped, and cells are taken from between the dividers
+func parseBox(inputString string) (table parsedTable) {
+	lines := strings.Split(inputString, "\n")
+	numHeaderLines := getNumHeaderLines()
+
+	// single column psql tables are the only ones without dividers
+	table.hasDividers = strings.ContainsAny(inputString, boxDividers)
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// skip empty, separator and trailing lines
+		if line == "" || isBoxSeparator(line) || psqlFooterRegex.MatchString(line) { continue }
+		if table.hasDividers && !strings.ContainsAny(line, boxDividers) { continue }
+
+		cells := splitBoxLine(line)
+		if len(table.headerRows) < numHeaderLines {
+			table.headerRows = append(table.headerRows, cells)
+		} else {
+			table.addRow(cells, i + 1)
+		}
+	}
+
+	return
+}
+
+// checks if a line only draws the table border
+func isBoxSeparator(line string) bool {
+	if !strings.ContainsAny(line, "-=─━═") { return false }
+
+	for _, r := range line {
+		if !strings.ContainsRune(boxSeparatorRunes, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// splits a line into trimmed cells, ignoring the outer border
+func splitBoxLine(line string) []string {
+	// markdown allows escaped pipes inside of cells
+	const escapedPipe = "\x00"
+	line = strings.ReplaceAll(line, `\|`, escapedPipe)
+
+	// remove outer border
+	for _, divider := range boxDividers {
+		line = strings.TrimPrefix(line, string(divider))
+		line = strings.TrimSuffix(line, string(divider))
+	}
+
+	// split on any divider
+	for _, divider := range boxDividers {
+		line = strings.ReplaceAll(line, string(divider), "|")
+	}
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.ReplaceAll(strings.TrimSpace(cells[i]), escapedPipe, "|")
+	}
+	return cells
+}
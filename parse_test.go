@@ -0,0 +1,68 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePositional(t *testing.T) {
+	tests := []struct {
+		name string
+		input string
+		headers []string
+		rows [][]string
+	}{
+		{
+			"ps with right aligned first column",
+			"    PID TTY          TIME CMD\n   1234 pts/0    00:00:00 bash\n  56789 pts/0    00:00:01 ps\n",
+			[]string{ "PID", "TTY", "TIME", "CMD" },
+			[][]string{ { "1234", "pts/0", "00:00:00", "bash" }, { "56789", "pts/0", "00:00:01", "ps" } },
+		},
+		{
+			"header starting with a space",
+			" A B\n 1 2\n",
+			[]string{ "A", "B" },
+			[][]string{ { "1", "2" } },
+		},
+		{
+			"multi-word header",
+			"NAME    CONTAINER ID   STATUS\nfoo     abc123         Up 2 hours\n",
+			[]string{ "NAME", "CONTAINER ID", "STATUS" },
+			[][]string{ { "foo", "abc123", "Up 2 hours" } },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := parsePositional(test.input)
+			if len(table.headerRows) != 1 || !slices.Equal(table.headerRows[0], test.headers) {
+				t.Errorf("headers = %q, want %q", table.headerRows, test.headers)
+			}
+			if !slices.EqualFunc(table.rows, test.rows, slices.Equal) {
+				t.Errorf("rows = %q, want %q", table.rows, test.rows)
+			}
+		})
+	}
+}
+
+func TestGetHeaderStartIndices(t *testing.T) {
+	headerLine := "    PID TTY          TIME CMD"
+
+	starts, err := getHeaderStartIndices(headerLine, []string{ "PID", "TTY", "TIME", "CMD" })
+	if err != nil || !slices.Equal(starts, []int{ 4, 8, 21, 26 }) {
+		t.Errorf("starts = %v (%v), want [4 8 21 26]", starts, err)
+	}
+
+	if _, err := getHeaderStartIndices(headerLine, []string{ "TTY", "PID" }); err == nil {
+		t.Error("headers out of order were accepted")
+	}
+	if _, err := getHeaderStartIndices(headerLine, []string{ "PID", "USER" }); err == nil {
+		t.Error("missing header was accepted")
+	}
+}
+
+func TestGetWordStartIndices(t *testing.T) {
+	if starts := getWordStartIndices("  xxx xx   x"); !slices.Equal(starts, []int{ 2, 6, 11 }) {
+		t.Errorf("starts = %v, want [2 6 11]", starts)
+	}
+}
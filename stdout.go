@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"strings"
+
+	// external
+	"github.com/mattn/go-runewidth"
 )
 
 func printTable()  {
@@ -19,12 +22,12 @@ func printTable()  {
 	var widths []int = make([]int, len(transformation.ColumnHeaders))
 	for c, header := range transformation.ColumnHeaders {
 		// header width
-		widths[c] = len(header)
+		widths[c] = runewidth.StringWidth(header)
 
 		// entry widths
 		column, _ := getColumnFromData(header)
 		for _, rindex := range outputEntryIndices {
-			valueLength := len(column[rindex])
+			valueLength := runewidth.StringWidth(column[rindex])
 			if (valueLength > widths[c]) {
 				widths[c] = valueLength
 			}
@@ -40,7 +43,7 @@ func printTable()  {
 		fake := isColumnFake(header)
 
 		// apply padding
-		header += strings.Repeat(" ", widths[c] - len(header))
+		header += strings.Repeat(" ", widths[c] - runewidth.StringWidth(header))
 		// colorize
 		if (shouldFluff) { header = colorizeAnsiCell(header, true, c, fake) }
 
@@ -58,11 +61,15 @@ func printTable()  {
 	for _, entryIdx := range outputEntryIndices {
 		for c, header := range transformation.ColumnHeaders {
 			value := outColumns[header][entryIdx]
+			padding := strings.Repeat(" ", widths[c] - runewidth.StringWidth(value))
 
-			// apply padding
-			value += strings.Repeat(" ", widths[c] - len(value))
-			// fluff
-			if (shouldFluff) { value = colorizeAnsiCell(value, false, c, isColumnFake(header)) }
+			// fluff (with the original colors if they were kept)
+			if (shouldFluff) {
+				if coloredValue := getColoredDataInColumn(header, entryIdx); coloredValue != "" { value = coloredValue + "\033[0m" }
+				value = colorizeAnsiCell(value + padding, false, c, isColumnFake(header))
+			} else {
+				value += padding
+			}
 
 			fmt.Print(value)
 		}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// input is read line by line in the background, so the TUI can show entries while the rest is still coming in.
+// the first chunk of each source is parsed like a whole input (to detect the mode and find the columns),
+// then the lines after it are parsed in batches the same way
+
+// the first chunk ends after this many lines, or after the wait once it has a header and a row
+const firstChunkMaxLines = 500
+const firstChunkWait = 500 * time.Millisecond
+
+// how often streamed lines are parsed and added
+const streamBatchInterval = 100 * time.Millisecond
+
+// how often a followed file is checked for new lines
+const followPollInterval = 250 * time.Millisecond
+
+// header of the column added by the sourceColumn flag
+const sourceColumnHeader = "source"
+
+// an input table's text, and where it came from
+type inputSource struct {
+	name string
+	reader io.Reader
+}
+
+// reads a file like tail -f, waiting for more to be written instead of ending
+type followReader struct {
+	file *os.File
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		if n > 0 || err != io.EOF { return n, err }
+
+		// start over if the file was truncated (log rotation)
+		position, _ := r.file.Seek(0, io.SeekCurrent)
+		if info, statErr := r.file.Stat(); statErr == nil && info.Size() < position {
+			r.file.Seek(0, io.SeekStart)
+		}
+		time.Sleep(followPollInterval)
+	}
+}
+
+func (r *followReader) Close() error {
+	return r.file.Close()
+}
+
+// lines of an input, the channel is closed at the end (err is set before that if reading failed)
+type lineStream struct {
+	lines chan string
+	err error
+}
+
+func streamLines(reader io.Reader) *lineStream {
+	stream := &lineStream{ lines: make(chan string, firstChunkMaxLines) }
+	go func() {
+		bufferedReader := bufio.NewReader(reader)
+		for {
+			line, err := bufferedReader.ReadString('\n')
+			if line != "" { stream.lines <- strings.TrimSuffix(line, "\n") }
+			if err != nil {
+				if err != io.EOF { stream.err = err }
+				close(stream.lines)
+				return
+			}
+		}
+	}()
+	return stream
+}
+
+// an error in the input that stops it from being loaded
+type inputError struct {
+	message string
+}
+
+// stops loading input because of an error in it (see loadInputs)
+func failInput(format string, args ...any) {
+	panic(inputError{ fmt.Sprintf(format, args...) })
+}
+
+// loads every input source into the input data (sources are concatenated)
+// live loading runs next to the TUI, changes are queued to its event loop and the output is updated as entries come in
+func loadInputs(sources []inputSource, live bool) (err error) {
+	updateData := func(update func()) {
+		if live {
+			app.QueueUpdateDraw(update)
+		} else {
+			update()
+		}
+	}
+
+	// errors in the input are returned when loading all at once, and are shown in the TUI (which still owns the terminal) with the entries loaded before them
+	defer func() {
+		recovered := recover()
+		if recovered == nil { return }
+		failure, ok := recovered.(inputError)
+		if !ok { panic(recovered) }
+		if !live {
+			err = errors.New(failure.message)
+			return
+		}
+
+		updateData(func() {
+			data.loading = false
+			data.parseWarning = failure.message
+			writeToMessageBuffer(failure.message)
+			updateInfoText()
+		})
+	}()
+	updateData(func() {
+		data.entriesByColumn = make(map[string][]string)
+		data.columnHeaders = nil
+		data.numEntries = 0
+		data.coloredEntriesByColumn = nil
+		data.columnTypes, data.typedColumns, data.sortedEntries = nil, nil, nil
+		data.loading = true
+		data.commandError = nil
+	})
+
+	// sets a source's warning, and shows every source's warnings
+	var parseModesUsed []string
+	warnings := make([]string, len(sources))
+	setWarning := func(i int, warning string) {
+		warnings[i] = warning
+		data.parseWarning = strings.Join(slices.DeleteFunc(slices.Clone(warnings), func(w string) bool { return w == "" }), "; ")
+	}
+
+	sourceHeader := ""
+	for i, source := range sources {
+		stream := streamLines(source.reader)
+
+		// adds parsed rows to the input data, and shows them
+		addRows := func(parser *streamParser, headers []string, table parsedTable, coloredEntriesByColumn map[string][]string) {
+			// add column with each entry's source
+			if *flags.sourceColumn {
+				if sourceHeader == "" { sourceHeader = uniqueHeaders(append(slices.Clone(headers), sourceColumnHeader))[len(headers)] }
+				headers = append(slices.Clone(headers), sourceHeader)
+				for r := range table.rows { table.rows[r] = append(table.rows[r], source.name) }
+			}
+
+			warning := getRaggedRowsWarning(parser.numMalformed, parser.firstMalformedLine)
+			if warning != "" && len(sources) > 1 { warning = source.name + ": " + warning }
+
+			updateData(func() {
+				oldNumEntries := data.numEntries
+				var newHeaders []string
+				for _, header := range headers {
+					if _, ok := data.entriesByColumn[header]; !ok { newHeaders = append(newHeaders, header) }
+				}
+				appendInputData(headers, table.rows, coloredEntriesByColumn)
+				addDefaultTransformationColumns(newHeaders)
+				numDropped := 0
+				if *flags.maxRows > 0 { numDropped = dropOldestEntries(data.numEntries - *flags.maxRows) }
+
+				setWarning(i, warning)
+				if live { refreshTuiTableWithNewEntries(oldNumEntries, numDropped) }
+			})
+		}
+
+		// parse the first chunk to find the columns
+		chunk, ended := readFirstChunk(stream)
+		headers, table, coloredEntriesByColumn := parseInput(strings.Join(chunk.lines, "\n"))
+		parser := newStreamParser(headers, table, len(chunk.lines))
+		if !slices.Contains(parseModesUsed, table.mode) { parseModesUsed = append(parseModesUsed, table.mode) }
+
+		parseMode := strings.Join(parseModesUsed, ", ")
+		updateData(func() {
+			data.parseMode = parseMode
+
+			// the first source's header is displayed
+			if i == 0 {
+				data.headerRows = table.headerRows
+				data.numHeaderRows = max(1, len(table.headerRows))
+			}
+		})
+		addRows(parser, headers, table, coloredEntriesByColumn)
+
+		// parse the rest in batches
+		if !ended {
+			pendingLines := chunk.leftover
+			ticker := time.NewTicker(streamBatchInterval)
+			for open := true; open; {
+				select {
+				case line, ok := <-stream.lines:
+					if ok { pendingLines = append(pendingLines, line) }
+					open = ok
+					if open { continue }
+				case <-ticker.C:
+				}
+				if len(pendingLines) == 0 { continue }
+
+				headers, table, coloredEntriesByColumn := parser.parseLines(pendingLines)
+				pendingLines = nil
+				if len(table.rows) > 0 { addRows(parser, headers, table, coloredEntriesByColumn) }
+			}
+			ticker.Stop()
+		}
+
+		// report input that couldn't be read (failed commands are reported on their own)
+		var commandErr *commandError
+		if errors.As(stream.err, &commandErr) {
+			updateData(func() { data.commandError = commandErr })
+		} else if stream.err != nil {
+			readWarning := fmt.Sprintf("could not read %v: %v", source.name, stream.err)
+			updateData(func() {
+				if warnings[i] != "" { readWarning = warnings[i] + "; " + readWarning }
+				setWarning(i, readWarning)
+			})
+		}
+		if closer, ok := source.reader.(io.Closer); ok { closer.Close() }
+	}
+
+	updateData(func() {
+		data.loading = false
+
+		// the entries of a failed command are only kept when asked for
+		if data.commandError != nil && !*flags.partialOutput { clearInputEntries() }
+
+		if live {
+			if data.parseWarning != "" { writeToMessageBuffer(data.parseWarning) }
+			if data.commandError != nil {
+				refilterTuiTable()
+				openCommandErrorMenu(data.commandError)
+			}
+			updateInfoText()
+		}
+	})
+	return nil
+}
+
+// removes every entry from the input data (the columns stay)
+func clearInputEntries() {
+	for header := range data.entriesByColumn { data.entriesByColumn[header] = nil }
+	data.coloredEntriesByColumn = nil
+	data.numEntries = 0
+	data.sortedEntries = nil
+}
+
+// the start of an input, cut where a quoted csv value doesn't run over into the lines after it
+type firstChunk struct {
+	lines []string
+	leftover []string
+}
+
+// reads the first chunk of an input, returns if the input ended in it
+func readFirstChunk(stream *lineStream) (chunk firstChunk, ended bool) {
+	timeout := time.After(firstChunkWait)
+	timedOut := false
+	for !isFirstChunkComplete(chunk.lines, timedOut) {
+		select {
+		case line, ok := <-stream.lines:
+			if !ok { return chunk, true }
+			chunk.lines = append(chunk.lines, line)
+		case <-timeout:
+			timedOut = true
+		}
+	}
+
+	// move the lines of an unfinished quoted value to after the chunk
+	cut := len(chunk.lines)
+	for quotes := strings.Count(strings.Join(chunk.lines, "\n"), `"`); quotes % 2 == 1 && cut > 0; {
+		cut--
+		quotes -= strings.Count(chunk.lines[cut], `"`)
+	}
+	if cut > 0 && cut < len(chunk.lines) {
+		// (only csv and tsv values are quoted, in other tables a quote is just text)
+		// the mode is found without the unfinished value, which the csv reader would reject
+		inputString, _, _ := trimInputLines(stripAnsi(strings.Join(chunk.lines[:cut], "\n")))
+		if mode := resolveParseMode(inputString); mode == "csv" || mode == "tsv" {
+			chunk.lines, chunk.leftover = chunk.lines[:cut], chunk.lines[cut:]
+		}
+	}
+
+	return chunk, false
+}
+
+func isFirstChunkComplete(lines []string, timedOut bool) bool {
+	// json documents can only be parsed whole
+	if len(lines) > 0 && (*flags.parseMode == "json" || *flags.parseMode == "auto") {
+		text := stripAnsi(strings.Join(lines[:min(detectSampleSize, len(lines))], "\n"))
+		trimmed := strings.TrimSpace(text)
+		if *flags.parseMode == "json" || (trimmed != "" && (trimmed[0] == '[' || trimmed[0] == '{') && !isJSONLines(text)) {
+			return false
+		}
+	}
+
+	if len(lines) >= firstChunkMaxLines { return true }
+	return timedOut && hasFirstRow(lines)
+}
+
+// checks if lines have the header, and a row after it (or a line under it, for box tables)
+func hasFirstRow(lines []string) bool {
+	lines = lines[min(transformation.SkipLines, len(lines)):]
+	if transformation.HeaderRegex != "" {
+		headerRegex := compileInputRegex(transformation.HeaderRegex)
+		headerIndex := slices.IndexFunc(lines, func(line string) bool { return headerRegex.MatchString(stripAnsi(line)) })
+		if headerIndex == -1 { return false }
+		lines = lines[headerIndex:]
+	}
+
+	return slices.ContainsFunc(lines[min(getNumHeaderLines() + 1, len(lines)):], func(line string) bool {
+		return strings.TrimSpace(line) != ""
+	})
+}
+
+// parses lines that come after an input's first chunk, cutting them up the same way
+type streamParser struct {
+	table parsedTable // the first chunk
+	headers []string
+	lineNumber int
+	numMalformed, firstMalformedLine int
+
+	stopRegex *regexp.Regexp
+	dropRepeatedHeaders bool
+	pendingRecord []string // lines of a quoted csv value that isn't finished
+}
+
+func newStreamParser(headers []string, table parsedTable, numLines int) *streamParser {
+	parser := &streamParser{
+		table: table,
+		headers: headers,
+		lineNumber: numLines,
+		numMalformed: table.numMalformed,
+		firstMalformedLine: table.firstMalformedLine,
+		dropRepeatedHeaders: transformation.DropRepeatedHeaders,
+	}
+	if transformation.StopRegex != "" { parser.stopRegex = compileInputRegex(transformation.StopRegex) }
+	return parser
+}
+
+func (p *streamParser) parseLines(rawLines []string) (headers []string, table parsedTable, coloredEntriesByColumn map[string][]string) {
+	table.mode = p.table.mode
+	lineOffset := p.lineNumber
+
+	var jsonLines []string
+	for _, rawLine := range rawLines {
+		p.lineNumber++
+		line := stripAnsi(rawLine)
+
+		// stop at terminator, and drop repeated headers
+		if p.table.stopped { continue }
+		if p.stopRegex != nil && p.stopRegex.MatchString(line) {
+			p.table.stopped = true
+			continue
+		}
+		if p.dropRepeatedHeaders && slices.ContainsFunc(p.table.headerLines, func(headerLine string) bool {
+			return strings.TrimSpace(headerLine) == strings.TrimSpace(line)
+		}) {
+			continue
+		}
+
+		switch table.mode {
+		case "csv", "tsv":
+			// wait for the end of quoted values that run over lines
+			p.pendingRecord = append(p.pendingRecord, line)
+			recordText := strings.Join(p.pendingRecord, "\n")
+			if strings.Count(recordText, `"`) % 2 == 1 { continue }
+			recordLine := p.lineNumber - len(p.pendingRecord) + 1
+			p.pendingRecord = nil
+			if strings.TrimSpace(recordText) == "" { continue }
+
+			reader := csv.NewReader(strings.NewReader(recordText))
+			reader.Comma = getDelimiter(table.mode)
+			reader.FieldsPerRecord = -1
+			record, err := reader.Read()
+			if err != nil {
+				failInput("Could not parse delimited input: %v", err)
+			}
+			table.addRow(record, recordLine)
+		case "json", "jsonl":
+			if strings.TrimSpace(line) != "" { jsonLines = append(jsonLines, line) }
+		case "box":
+			line = strings.TrimSpace(line)
+			if line == "" || isBoxSeparator(line) || psqlFooterRegex.MatchString(line) { continue }
+			if p.table.hasDividers && !strings.ContainsAny(line, boxDividers) { continue }
+			table.addRow(splitBoxLine(line), p.lineNumber)
+		case "whitespace":
+			if strings.TrimSpace(line) == "" { continue }
+			table.addRow(strings.Fields(line), p.lineNumber)
+		default:
+			if len(line) == 0 { continue }
+			table.addRow(sliceColumns(line, p.table.columnStarts), p.lineNumber)
+		}
+	}
+
+	// json records bring their own keys (new keys become new columns)
+	headers = p.headers
+	if len(jsonLines) > 0 {
+		table = parseJSON(strings.Join(jsonLines, "\n"), true)
+		table.mode = p.table.mode
+		headers = getHeaderNames(table.headerRows, table.rows)
+	}
+
+	fixRaggedRows(&table, len(headers))
+	if p.numMalformed == 0 { p.firstMalformedLine = table.firstMalformedLine }
+	p.numMalformed += table.numMalformed
+
+	// find the original colors of entries
+	if *flags.keepColors {
+		coloredEntriesByColumn = getColoredEntries(rawLines, lineOffset, headers, table)
+	}
+
+	return
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadInputsWithQuoteInPositionalInput(t *testing.T) {
+	// (long enough for the first chunk to end before the input does)
+	lines := []string{ "NAME   LAST SEEN   NOTE", `a      5m ago      say "hi` }
+	for i := 0; i < 700; i++ {
+		lines = append(lines, fmt.Sprintf("b%-5d 1h ago      ok", i))
+	}
+	loadInputs([]inputSource{ { "test", strings.NewReader(strings.Join(lines, "\n") + "\n") } }, false)
+
+	if headers := []string{ "NAME", "LAST SEEN", "NOTE" }; !slices.Equal(data.columnHeaders, headers) {
+		t.Errorf("headers = %q, want %q", data.columnHeaders, headers)
+	}
+	if data.numEntries != 701 {
+		t.Errorf("numEntries = %v, want 701", data.numEntries)
+	}
+	if note := data.entriesByColumn["NOTE"][0]; note != `say "hi` {
+		t.Errorf("first note = %q, want %q", note, `say "hi`)
+	}
+}
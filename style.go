@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/JackJ30/jack-tview"
 )
@@ -13,7 +16,7 @@ const (
 	SelectionNone
 )
 
-func colorizeTCell(cell *tview.TableCell, isHeader bool, column int, fake bool, empty bool, selection CellSelectStatus) *tview.TableCell {
+func colorizeTCell(cell *tview.TableCell, isHeader bool, column int, fake bool, empty bool, changed bool, selection CellSelectStatus) *tview.TableCell {
 	var backgroundColor tcell.Color = tcell.ColorDefault
 	var textColor = tcell.ColorDefault
 
@@ -38,6 +41,9 @@ func colorizeTCell(cell *tview.TableCell, isHeader bool, column int, fake bool,
 		} else {
 			textColor = tcell.ColorLightBlue
 		}
+
+		// changed by a refresh
+		if changed { backgroundColor = tcell.ColorOlive }
 	}
 
 	// insane selection logic
@@ -87,22 +93,58 @@ func colorizeAnsiCell(value string, isHeader bool, column int, fake bool) string
 	return value + "\033[0m"
 }
 
-func decorateHeader(header string) string {
-	decoratedHeader := header
+// gets the text of a header on a header row
+// multi-line headers are spread over the header rows, and only the last row is decorated
+func decorateHeader(header string, row int) string {
+	decoratedHeader := getHeaderRowText(header, row)
+	if row < data.numHeaderRows - 1 {
+		return decoratedHeader
+	}
 
-	if transformation.SortByColumn == header {
-		if transformation.SortAscending {
-			decoratedHeader += "(↑)"
-		} else {
-			decoratedHeader += "(↓)"
-		}
+	if columnType := getColumnType(header); columnType != "text" {
+		decoratedHeader += "(" + columnType + ")"
+	}
+
+	if sortKeyIndex := getSortKeyIndex(header); sortKeyIndex != -1 {
+		sortKey := transformation.SortKeys[sortKeyIndex]
+
+		// (priority is shown when sorting by more than one column, and sort modes other than the default are named)
+		sortText := "↑"
+		if !sortKey.Ascending { sortText = "↓" }
+		if len(transformation.SortKeys) > 1 { sortText += fmt.Sprint(sortKeyIndex + 1) }
+		if sortKey.Mode != "" { sortText += " " + sortKey.Mode }
+
+		decoratedHeader += "(" + sortText + ")"
 	}
 
+	// (invalid filters are disabled, which is flagged)
+	filterText := ""
 	if _, includeFound := transformation.IncludeRegexByColumn[header]; includeFound {
-		decoratedHeader += "(F)"
+		filterText = "(F)"
 	} else if _, excludeFound := transformation.ExcludeRegexByColumn[header]; excludeFound {
-		decoratedHeader += "(F)"
+		filterText = "(F)"
+	} else if _, compareFound := transformation.CompareFilterByColumn[header]; compareFound {
+		filterText = "(F)"
 	}
+	if filterText != "" && getColumnFilterError(header) != nil { filterText = "(F!)" }
+	decoratedHeader += filterText
 
 	return decoratedHeader
 }
+
+func getHeaderRowText(header string, row int) string {
+	column := slices.Index(data.columnHeaders, header)
+
+	// single line (and fake) headers sit on the last header row
+	if len(data.headerRows) <= 1 || column == -1 {
+		if row == data.numHeaderRows - 1 {
+			return header
+		}
+		return ""
+	}
+
+	if column >= len(data.headerRows[row]) {
+		return ""
+	}
+	return data.headerRows[row][column]
+}
@@ -151,19 +151,25 @@ func (d *TableData) GetCell(row, column int) *tview.TableCell {
 
 	if row < data.numHeaderRows {
 		// if header
-		content := decorateHeader(header)
-		cell = colorizeTCell(tview.NewTableCell(content).SetAlign(tview.AlignCenter), true, column, fake, false, selectionMode)
+		content := decorateHeader(header, row)
+		cell = colorizeTCell(tview.NewTableCell(content).SetAlign(tview.AlignCenter), true, column, fake, false, false, selectionMode)
 	} else if len(outputEntryIndices) == 0 {
 		// if "empty" entry
-		cell = colorizeTCell(tview.NewTableCell("EMPTY").SetAlign(tview.AlignCenter).SetClickedFunc(func() bool {
+		emptyText := "EMPTY"
+		if data.loading { emptyText = "LOADING" }
+		cell = colorizeTCell(tview.NewTableCell(emptyText).SetAlign(tview.AlignCenter).SetClickedFunc(func() bool {
 			return tableModeStack[len(tableModeStack) - 1].onClicked(row, column)
-		}), false, column, fake, true, selectionMode)
+		}), false, column, fake, true, false, selectionMode)
 	} else {
-		// if entry
-		content := getDataInColumn(header, outputEntryIndices[row - data.numHeaderRows])
+		// if entry (colored input is translated into color tags)
+		entry := outputEntryIndices[row - data.numHeaderRows]
+		content := getDataInColumn(header, entry)
+		if coloredContent := getColoredDataInColumn(header, entry); coloredContent != "" {
+			content = tview.TranslateANSI(coloredContent)
+		}
 		cell = colorizeTCell(tview.NewTableCell(content).SetAlign(tview.AlignCenter).SetClickedFunc(func() bool {
 			return tableModeStack[len(tableModeStack) - 1].onClicked(row, column)
-		}), false, column, fake, false, selectionMode)
+		}), false, column, fake, false, isCellChanged(header, entry), selectionMode)
 	}
 
 	return cell
@@ -1,31 +1,94 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"regexp"
 	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // transformation config
 type TransformationConfig struct {
 	ColumnHeaders []string
-	SortByColumn string
-	SortAscending bool
+	SortKeys []SortKey // in order of priority
 	IncludeRegexByColumn map[string]string
 	ExcludeRegexByColumn map[string]string
+	IncludeOptionsByColumn map[string]FilterOptions `json:",omitempty"`
+	ExcludeOptionsByColumn map[string]FilterOptions `json:",omitempty"`
+	CompareFilterByColumn map[string]string `json:",omitempty"` // comparisons like "> 10", "1h..2h" or "in (a, b)"
+	FilterExpression string `json:",omitempty"` // filters entries by any of their columns (see expression.go)
+	ColumnTypes map[string]string `json:",omitempty"` // overrides inferred types
+
+	// parsing options (so presets work for commands with preambles and footers)
+	SkipLines int
+	HeaderRegex string
+	StopRegex string
+	DropRepeatedHeaders bool
+
+	// input (optional, so a preset can fetch and parse its own table)
+	Command string `json:",omitempty"`
+	ParseMode string `json:",omitempty"`
+	Delimiter string `json:",omitempty"`
+	Headers string `json:",omitempty"`
+	ColumnWidths string `json:",omitempty"`
+	HeaderRows int `json:",omitempty"`
+	NoHeader bool `json:",omitempty"`
+	RaggedRows string `json:",omitempty"`
+
+	// single column sort of older presets (moved into the sort keys when loaded)
+	SortByColumn string `json:",omitempty"`
+	SortAscending bool `json:",omitempty"`
 }
 var transformation TransformationConfig = TransformationConfig{
 	nil,
-	"",
-	true,
+	nil,
+	make(map[string]string),
 	make(map[string]string),
+	nil,
+	nil,
 	make(map[string]string),
+	"",
+	nil,
+	0,
+	"",
+	"",
+	false,
+	"",
+	"",
+	"",
+	"",
+	"",
+	0,
+	false,
+	"",
+	"",
+	false,
+}
+
+// a column that is sorted by
+type SortKey struct {
+	Column string
+	Ascending bool
+	Mode string `json:",omitempty"` // one of sortModes ("" is typed)
 }
 
+// how a column's include or exclude pattern matches
+type FilterOptions struct {
+	Syntax string `json:",omitempty"` // one of filterSyntaxes ("" is regex)
+	IgnoreCase bool `json:",omitempty"`
+	WholeCell bool `json:",omitempty"` // the pattern has to match the whole cell, not just part of it
+}
+var filterSyntaxes = []string{ "regex", "literal", "glob" }
+
 // presets
 const presetsFilename = "presets"
 var presetTransformations map[string]TransformationConfig = make(map[string]TransformationConfig)
@@ -35,10 +98,17 @@ var activePresetName string = ""
 var outputEntryIndices []int
 
 func initializeTransformation() {
+	// parsing option flags override the loaded transformation, and its input fills in the flags that weren't given
+	defer applyTransformationFlags()
+	defer applyTransformationInput()
 
 	// load presets from file
 	if data, err := os.ReadFile(configDir + presetsFilename); err == nil {
 		json.Unmarshal(data, &presetTransformations)
+		for name, preset := range presetTransformations {
+			upgradeSortKeys(&preset)
+			presetTransformations[name] = preset
+		}
 	}
 
 	// if we have a preset, load that
@@ -67,10 +137,69 @@ func initializeTransformation() {
 
 		return
 	}
+}
+
+// adds columns to the default transformation as they are found in the input (if none was loaded)
+func addDefaultTransformationColumns(headers []string) {
+	if *flags.preset != "" || *flags.loadPath != "" { return }
 
-	// generate default transformation
-	transformation.ColumnHeaders = make([]string, len(data.columnHeaders))
-	copy(transformation.ColumnHeaders, data.columnHeaders)
+	for _, header := range headers {
+		if !slices.Contains(transformation.ColumnHeaders, header) {
+			transformation.ColumnHeaders = append(transformation.ColumnHeaders, header)
+		}
+	}
+}
+
+// copies the flags that are saved with the transformation (if they were set)
+func applyTransformationFlags() {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "skipLines":
+			transformation.SkipLines = *flags.skipLines
+		case "headerRegex":
+			transformation.HeaderRegex = *flags.headerRegex
+		case "stopRegex":
+			transformation.StopRegex = *flags.stopRegex
+		case "dropRepeatedHeaders":
+			transformation.DropRepeatedHeaders = *flags.dropRepeatedHeaders
+		}
+	})
+}
+
+// sets the input flags that weren't given to the input saved with the transformation
+func applyTransformationInput() {
+	givenFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { givenFlags[f.Name] = true })
+
+	fillFlag := func(name string, value string) {
+		if value != "" && !givenFlags[name] { flag.Set(name, value) }
+	}
+	fillFlag("command", transformation.Command)
+	fillFlag("parseMode", transformation.ParseMode)
+	fillFlag("delimiter", transformation.Delimiter)
+	fillFlag("headers", transformation.Headers)
+	fillFlag("columnWidths", transformation.ColumnWidths)
+	if transformation.HeaderRows > 0 { fillFlag("headerRows", strconv.Itoa(transformation.HeaderRows)) }
+	if transformation.NoHeader { fillFlag("noHeader", "true") }
+	fillFlag("raggedRows", transformation.RaggedRows)
+}
+
+// saves the command and how its output is parsed with the transformation (or clears them)
+func recordTransformationInput(record bool) {
+	transformation.Command, transformation.ParseMode, transformation.Delimiter, transformation.RaggedRows = "", "", "", ""
+	transformation.Headers, transformation.ColumnWidths = "", ""
+	transformation.HeaderRows, transformation.NoHeader = 0, false
+	if !record { return }
+
+	// (the detected parse mode is saved, so the preset doesn't depend on detection)
+	transformation.Command = refreshableCommand
+	transformation.ParseMode = data.parseMode
+	transformation.Delimiter = *flags.delimiter
+	transformation.Headers = *flags.headers
+	transformation.ColumnWidths = *flags.columnWidths
+	transformation.HeaderRows = *flags.headerRows
+	transformation.NoHeader = *flags.noHeader
+	transformation.RaggedRows = *flags.raggedRows
 }
 
 func serializeTransformation() ([]byte, error) {
@@ -82,7 +211,11 @@ func serializeTransformation() ([]byte, error) {
 }
 
 func deserializeTransformation(data []byte) error {
-	return json.Unmarshal(data, &transformation)
+	if err := json.Unmarshal(data, &transformation); err != nil {
+		return err
+	}
+	upgradeSortKeys(&transformation)
+	return nil
 }
 
 // PRESETS ====================================================================================================
@@ -117,6 +250,9 @@ func deepCopyPreset(preset TransformationConfig) (out TransformationConfig) {
 
 // TRANSFORM LOGIC ===========================================================================================
 
+// text shown in place of a value that doesn't exist
+const noDataText = "NO DATA"
+
 func isColumnFake(header string) bool {
 	return !slices.Contains(data.columnHeaders, header)
 }
@@ -130,7 +266,7 @@ func getColumnFromData(header string) (column []string, fake bool) {
 		// make fake column
 		column = make([]string, data.numEntries)
 		for i := range column {
-			column[i] = "NO DATA"
+			column[i] = noDataText
 		}
 		return column, true
 	}
@@ -141,7 +277,7 @@ func getDataInColumn(header string, entry int) string {
 	if ok {
 		return column[entry]
 	} else {
-		return "NO DATA"
+		return noDataText
 	}
 }
 
@@ -155,48 +291,358 @@ func filterInts(ss []int, test func(int) bool) (ret []int) {
     return
 }
 
+// every entry in sorted order, and the sort it is for
+// (kept so changing filters doesn't sort again, which keeps refiltering fast enough to run while typing)
+type sortedEntries struct {
+	sort string
+	indices []int
+}
+
 func transformDataToOutput() {
+	outputEntryIndices = filterInts(getSortedEntries(), getEntryFilter())
+}
 
-	// generate default output (all of input)
-	outputEntryIndices = make([]int, data.numEntries)
-	for i := 0; i < len(outputEntryIndices); i++ { outputEntryIndices[i] = i }
+// gets every entry in sorted order (sorted again only when the sort or the entries change)
+func getSortedEntries() []int {
+	// (the sort depends on the sort keys and the types of their columns)
+	sortText := fmt.Sprint(transformation.SortKeys)
+	for _, sortKey := range transformation.SortKeys {
+		if _, found := data.entriesByColumn[sortKey.Column]; found { sortText += " " + getColumnType(sortKey.Column) }
+	}
+	if data.sortedEntries != nil && data.sortedEntries.sort == sortText && len(data.sortedEntries.indices) == data.numEntries {
+		return data.sortedEntries.indices
+	}
+
+	indices := make([]int, data.numEntries)
+	for i := range indices { indices[i] = i }
+	if less := getEntryComparator(); less != nil {
+		sort.SliceStable(indices, func(i, j int) bool {
+			return less(indices[i], indices[j])
+		})
+	}
+
+	data.sortedEntries = &sortedEntries{ sortText, indices }
+	return indices
+}
+
+// adds the entries from startEntry on to the output (sorted into place)
+// so entries that stream in don't re-transform the whole input
+func transformNewEntriesToOutput(startEntry int) {
+	newEntryIndices := make([]int, data.numEntries - startEntry)
+	for i := range newEntryIndices { newEntryIndices[i] = startEntry + i }
 
 	// filter by regex
+	newEntryIndices = filterInts(newEntryIndices, getEntryFilter())
+
+	// sort
+	less := getEntryComparator()
+	if less == nil {
+		outputEntryIndices = append(outputEntryIndices, newEntryIndices...)
+		return
+	}
+	sort.SliceStable(newEntryIndices, func(i, j int) bool {
+		return less(newEntryIndices[i], newEntryIndices[j])
+	})
+	outputEntryIndices = mergeSortedEntries(outputEntryIndices, newEntryIndices, less)
+}
+
+// removes dropped entries from the output (entries after them move down by count)
+func dropOutputEntries(count int) {
+	outputEntryIndices = slices.DeleteFunc(outputEntryIndices, func(entryIndex int) bool { return entryIndex < count })
+	for i := range outputEntryIndices { outputEntryIndices[i] -= count }
+}
+
+// gets a test for entries that pass the filters (regexes are only compiled once)
+func getEntryFilter() func(entryIndex int) bool {
+	var tests []func(entryIndex int) bool
 	for _, columnHeader := range transformation.ColumnHeaders {
 		if !slices.Contains(data.columnHeaders, columnHeader) { continue } // skip over if header not in data
 		entries := data.entriesByColumn[columnHeader]
 
-		// run include regex
+		// include regex
 		includeRegex, includeFound := transformation.IncludeRegexByColumn[columnHeader]
 		if (includeFound) {
-			compiledReg := regexp.MustCompile(includeRegex)
-			outputEntryIndices = filterInts(outputEntryIndices, func(entryIndex int) bool {
-				return compiledReg.MatchString(entries[entryIndex])
-			})
+			if compiledReg, err := compileFilterPattern(includeRegex, transformation.IncludeOptionsByColumn[columnHeader]); err == nil {
+				tests = append(tests, func(entryIndex int) bool {
+					return compiledReg.MatchString(entries[entryIndex])
+				})
+			}
 		}
 
-		// run exclude regex
+		// exclude regex
 		excludeRegex, excludeFound := transformation.ExcludeRegexByColumn[columnHeader]
 		if (excludeFound) {
-			compiledReg := regexp.MustCompile(excludeRegex)
-			outputEntryIndices = filterInts(outputEntryIndices, func(entryIndex int) bool {
-				return !compiledReg.MatchString(entries[entryIndex])
-			})
+			if compiledReg, err := compileFilterPattern(excludeRegex, transformation.ExcludeOptionsByColumn[columnHeader]); err == nil {
+				tests = append(tests, func(entryIndex int) bool {
+					return !compiledReg.MatchString(entries[entryIndex])
+				})
+			}
+		}
+
+		// comparison
+		if compareFilter, compareFound := transformation.CompareFilterByColumn[columnHeader]; compareFound {
+			if parsed, err := parseComparison(compareFilter); err == nil && validateComparisonOperands(parsed, columnHeader) == nil {
+				tests = append(tests, getComparisonTest(parsed, columnHeader))
+			}
 		}
 	}
 
-	// sort
-	columnToSortBy, found := data.entriesByColumn[transformation.SortByColumn]
-	if (found) {
-		sort.Slice(outputEntryIndices, func(i, j int) bool {
-			valA := columnToSortBy[outputEntryIndices[i]] 
-			valB := columnToSortBy[outputEntryIndices[j]]
-
-			if transformation.SortAscending {
-				return valA < valB
-			} else {
-				return valA > valB
+	// expression
+	if transformation.FilterExpression != "" {
+		if expression, err := parseFilterExpression(transformation.FilterExpression); err == nil && validateExpressionOperands(expression) == nil {
+			tests = append(tests, getExpressionTest(expression))
+		}
+	}
+
+	return func(entryIndex int) bool {
+		for _, test := range tests {
+			if !test(entryIndex) { return false }
+		}
+		return true
+	}
+}
+
+// checks a column's filters (filters that don't compile are skipped when filtering)
+func validateColumnFilter(header string, includeRegex string, includeOptions FilterOptions, excludeRegex string, excludeOptions FilterOptions, compareFilter string) error {
+	if _, err := compileFilterPattern(includeRegex, includeOptions); err != nil {
+		return fmt.Errorf("include pattern: %v", err)
+	}
+	if _, err := compileFilterPattern(excludeRegex, excludeOptions); err != nil {
+		return fmt.Errorf("exclude pattern: %v", err)
+	}
+	if strings.TrimSpace(compareFilter) != "" {
+		parsed, err := parseComparison(compareFilter)
+		if err == nil { err = validateComparisonOperands(parsed, header) }
+		if err != nil {
+			return fmt.Errorf("comparison: %v", err)
+		}
+	}
+	return nil
+}
+
+func getColumnFilterError(header string) error {
+	return validateColumnFilter(
+		header,
+		transformation.IncludeRegexByColumn[header], transformation.IncludeOptionsByColumn[header],
+		transformation.ExcludeRegexByColumn[header], transformation.ExcludeOptionsByColumn[header],
+		transformation.CompareFilterByColumn[header],
+	)
+}
+
+func getExpressionFilterError() error {
+	if transformation.FilterExpression == "" { return nil }
+	expression, err := parseFilterExpression(transformation.FilterExpression)
+	if err != nil { return err }
+	return validateExpressionOperands(expression)
+}
+
+// describes the filters of the transformation that are disabled because they don't compile
+func getFilterErrors() []string {
+	var filterErrors []string
+	for _, header := range transformation.ColumnHeaders {
+		if err := getColumnFilterError(header); err != nil {
+			filterErrors = append(filterErrors, fmt.Sprintf("Disabled invalid filter on column %v (%v)", header, err))
+		}
+	}
+	if err := getExpressionFilterError(); err != nil {
+		filterErrors = append(filterErrors, fmt.Sprintf("Disabled invalid row filter (%v)", err))
+	}
+	return filterErrors
+}
+
+// sets (or removes) a column's filters
+func setColumnFilter(header string, includeRegex string, includeOptions FilterOptions, excludeRegex string, excludeOptions FilterOptions, compareFilter string) {
+	if includeRegex != "" {
+		transformation.IncludeRegexByColumn[header] = includeRegex
+	} else {
+		delete(transformation.IncludeRegexByColumn, header)
+	}
+
+	if excludeRegex != "" {
+		transformation.ExcludeRegexByColumn[header] = excludeRegex
+	} else {
+		delete(transformation.ExcludeRegexByColumn, header)
+	}
+
+	// (default options aren't saved)
+	setFilterOptions(&transformation.IncludeOptionsByColumn, header, includeRegex, includeOptions)
+	setFilterOptions(&transformation.ExcludeOptionsByColumn, header, excludeRegex, excludeOptions)
+
+	if strings.TrimSpace(compareFilter) != "" {
+		if transformation.CompareFilterByColumn == nil { transformation.CompareFilterByColumn = make(map[string]string) }
+		transformation.CompareFilterByColumn[header] = strings.TrimSpace(compareFilter)
+	} else {
+		delete(transformation.CompareFilterByColumn, header)
+	}
+}
+
+func setFilterOptions(optionsByColumn *map[string]FilterOptions, header string, pattern string, options FilterOptions) {
+	if pattern == "" || options == (FilterOptions{}) {
+		delete(*optionsByColumn, header)
+		return
+	}
+	if *optionsByColumn == nil { *optionsByColumn = make(map[string]FilterOptions) }
+	(*optionsByColumn)[header] = options
+}
+
+// compiles an include or exclude pattern into a regex by its options
+func compileFilterPattern(pattern string, options FilterOptions) (*regexp.Regexp, error) {
+	switch options.Syntax {
+	case "literal":
+		pattern = regexp.QuoteMeta(pattern)
+	case "glob":
+		pattern = globToRegex(pattern)
+	}
+
+	if options.WholeCell { pattern = "^(?:" + pattern + ")$" }
+	if options.IgnoreCase { pattern = "(?i)" + pattern }
+	return regexp.Compile(pattern)
+}
+
+// converts a glob (* is any text, ? is any character and [abc] or [!abc] is a set) to a regex
+func globToRegex(glob string) string {
+	var pattern strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i + 1:], ']')
+			if end <= 0 {
+				pattern.WriteString(`\[`)
+				continue
 			}
-		})
+			set := glob[i + 1:i + 1 + end]
+			if strings.HasPrefix(set, "!") { set = "^" + set[1:] }
+			pattern.WriteString("[" + set + "]")
+			i += end + 1
+		default:
+			pattern.WriteString(regexp.QuoteMeta(glob[i:i + 1]))
+		}
+	}
+	return pattern.String()
+}
+
+// gets the order of entries in the output, or nil if they aren't sorted
+// (sort keys are compared in order, and entries that tie on every key are left in input order)
+func getEntryComparator() func(a, b int) bool {
+	var compares []func(a, b int) int
+	for _, sortKey := range transformation.SortKeys {
+		if _, found := data.entriesByColumn[sortKey.Column]; !found { continue }
+
+		compare := getColumnComparator(sortKey.Column, sortKey.Mode)
+		if !sortKey.Ascending {
+			ascendingCompare := compare
+			compare = func(a, b int) int { return -ascendingCompare(a, b) }
+		}
+		compares = append(compares, compare)
+	}
+	if len(compares) == 0 { return nil }
+
+	return func(a, b int) bool {
+		for _, compare := range compares {
+			if result := compare(a, b); result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	}
+}
+
+// gets the index of a column in the sort keys, or -1 if it isn't sorted
+func getSortKeyIndex(header string) int {
+	return slices.IndexFunc(transformation.SortKeys, func(sortKey SortKey) bool { return sortKey.Column == header })
+}
+
+// moves the single column sort of older transformations into the sort keys
+func upgradeSortKeys(config *TransformationConfig) {
+	if config.SortByColumn != "" && len(config.SortKeys) == 0 {
+		config.SortKeys = []SortKey{ { config.SortByColumn, config.SortAscending, "" } }
+	}
+	config.SortByColumn, config.SortAscending = "", false
+}
+
+// orders that columns can be sorted in
+// typed compares values by the column's type, natural compares numbers in text by value (pod-2 before pod-10),
+// nocase ignores case, and text compares the raw text
+var sortModes = []string{ "typed", "natural", "nocase", "text" }
+
+// compares entries by their values in a column
+func getColumnComparator(header string, sortMode string) func(a, b int) int {
+	entries := data.entriesByColumn[header]
+
+	switch sortMode {
+	case "natural":
+		return func(a, b int) int { return compareNatural(entries[a], entries[b]) }
+	case "nocase":
+		return func(a, b int) int { return compareIgnoringCase(entries[a], entries[b]) }
+	case "text":
+		return func(a, b int) int { return strings.Compare(entries[a], entries[b]) }
+	default:
+		// (values that aren't of the column's type are compared as text)
+		typedValues := getTypedColumn(header)
+		return func(a, b int) int {
+			if typedValues[a] == nil && typedValues[b] == nil {
+				return strings.Compare(entries[a], entries[b])
+			}
+			return compareTypedValues(typedValues[a], typedValues[b])
+		}
+	}
+}
+
+// compares text, with runs of digits compared by their value
+func compareNatural(a string, b string) int {
+	for a != "" && b != "" {
+		// compare numbers by length (without leading zeros), then digit by digit
+		aDigits, bDigits := countLeadingDigits(a), countLeadingDigits(b)
+		if aDigits > 0 && bDigits > 0 {
+			aNumber, bNumber := strings.TrimLeft(a[:aDigits], "0"), strings.TrimLeft(b[:bDigits], "0")
+			if result := cmp.Compare(len(aNumber), len(bNumber)); result != 0 { return result }
+			if result := strings.Compare(aNumber, bNumber); result != 0 { return result }
+
+			a, b = a[aDigits:], b[bDigits:]
+			continue
+		}
+
+		aRune, aSize := utf8.DecodeRuneInString(a)
+		bRune, bSize := utf8.DecodeRuneInString(b)
+		if aRune != bRune { return cmp.Compare(aRune, bRune) }
+		a, b = a[aSize:], b[bSize:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func countLeadingDigits(text string) int {
+	count := 0
+	for count < len(text) && text[count] >= '0' && text[count] <= '9' { count++ }
+	return count
+}
+
+// compares text as if it were all lower case (without copying it)
+func compareIgnoringCase(a string, b string) int {
+	for a != "" && b != "" {
+		aRune, aSize := utf8.DecodeRuneInString(a)
+		bRune, bSize := utf8.DecodeRuneInString(b)
+		if result := cmp.Compare(unicode.ToLower(aRune), unicode.ToLower(bRune)); result != 0 { return result }
+		a, b = a[aSize:], b[bSize:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// merges sorted lists of entries (entries already in the output stay first when equal)
+func mergeSortedEntries(output []int, newEntries []int, less func(a, b int) bool) []int {
+	merged := make([]int, 0, len(output) + len(newEntries))
+	for len(output) > 0 && len(newEntries) > 0 {
+		if less(newEntries[0], output[0]) {
+			merged = append(merged, newEntries[0])
+			newEntries = newEntries[1:]
+		} else {
+			merged = append(merged, output[0])
+			output = output[1:]
+		}
 	}
+	merged = append(merged, output...)
+	return append(merged, newEntries...)
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"slices"
+	"strings"
 
 	"golang.design/x/clipboard"
 
@@ -15,6 +16,7 @@ import (
 
 // live data
 var controlPanelVisible = true
+var autoScroll = false
 
 // global tui objects
 var app *tview.Application
@@ -24,13 +26,16 @@ var messageBuffer *tview.TextView
 var instructionsText *tview.TextView
 var infoText *tview.TextView
 
-func setupTui()  {
+func setupTui(inputSources []inputSource)  {
 	// initialize clipboard lib
 	err := clipboard.Init()
 	if err != nil {
 		fmt.Println("Using the clipboard is not supported")
 	}
 	
+	// follow mode starts scrolled to new entries
+	autoScroll = *flags.follow
+
 	// initialize tview
 	app = tview.NewApplication()
 	app.EnableMouse(true)
@@ -43,7 +48,7 @@ func setupTui()  {
 	flex.AddItem(leftFlex, 0, 5, true)
 	pages = tview.NewPages()
 	leftFlex.AddItem(pages, 0, 1, true)
-	messageBuffer = tview.NewTextView().SetText("")
+	messageBuffer = tview.NewTextView().SetText(strings.Join(getFilterErrors(), "; "))
 	messageBuffer.SetBackgroundColor(tcell.ColorDimGrey)
 	leftFlex.AddItem(messageBuffer, 1, 0, false)
 
@@ -90,6 +95,10 @@ func setupTui()  {
 	// modes
 	appendMode(ColumnMode{})
 
+	// load input in the background
+	go loadInputs(inputSources, true)
+	if *flags.watch > 0 { startWatching(*flags.watch) }
+
 	// run tview
 	if err := app.SetRoot(flex, true).Run(); err != nil {
 		panic(err)
@@ -99,7 +108,23 @@ func setupTui()  {
 // UTILITIES ================================================================================
 
 func updateInfoText() {
-	infoText.SetText(fmt.Sprintf("[orange::b]Info[w::-]\nNum entries (after filter): %v", len(outputEntryIndices)))
+	parseModeText := data.parseMode
+	if *flags.parseMode == "auto" { parseModeText += " (detected)" }
+
+	statusText := ""
+	if getExpressionFilterError() != nil {
+		statusText += "\n[red]Row filter: invalid (disabled)[w]"
+	} else if transformation.FilterExpression != "" {
+		statusText += "\nRow filter: on"
+	}
+	if autoScroll { statusText += "\nAuto scroll: on" }
+	if data.loading && *flags.follow {
+		statusText += "\n[yellow]Following input...[w]"
+	} else if data.loading {
+		statusText += "\n[yellow]Loading input...[w]"
+	}
+
+	infoText.SetText(fmt.Sprintf("[orange::b]Info[w::-]\nNum entries (after filter): %v\nParse mode: %v%v", len(outputEntryIndices), parseModeText, statusText))
 }
 
 // call when data transformations are updated instead of generateTransformedOutput
@@ -123,6 +148,26 @@ func refilterTuiTable() {
 	// }
 }
 
+// call when entries are added to the input data (while it is loading)
+func refreshTuiTableWithNewEntries(oldNumEntries int, numDropped int) {
+	dropOutputEntries(numDropped)
+	transformNewEntriesToOutput(max(oldNumEntries - numDropped, 0))
+	updateInfoText()
+
+	// the header is only known once the first entries are parsed
+	table.SetFixed(data.numHeaderRows, 0)
+	selR = min(max(selR, data.numHeaderRows), tableData.GetRowCount() - 1)
+	selC = max(selC, 0)
+
+	if autoScroll { scrollToLastRow() }
+}
+
+func scrollToLastRow() {
+	selR = tableData.GetRowCount() - 1
+	offsetR = max(selR - table.GetVisibleRowCount() + 1, 0)
+	table.SetOffset(offsetR, offsetC)
+}
+
 const lastPresetName = "last"
 func exitTui() {
 
@@ -225,10 +270,22 @@ func openSaveMenu() {
 		name = text
 	})
 
+	// command (and parsing flags) checkbox, so the preset can be used without giving the command again
+	recordCommand := transformation.Command != ""
+	if refreshableCommand != "" {
+		form.AddCheckbox("Save command", recordCommand, func(checked bool) {
+			recordCommand = checked
+		})
+	}
+	recordInput := func() {
+		if refreshableCommand != "" { recordTransformationInput(recordCommand) }
+	}
+
 	// save as preset button
 	form.AddButton("Save as preset", func() {
 
 		if name == "" { return }
+		recordInput()
 
 		// save to preset list
 		presetTransformations[name] = deepCopyPreset(transformation)
@@ -243,6 +300,7 @@ func openSaveMenu() {
 	// save as file button
 	form.AddButton("Save as file", func() {
 		if name == "" { return }
+		recordInput()
 
 		json, _ := serializeTransformation()
 		err := os.WriteFile(name, json, 0666)
@@ -257,6 +315,7 @@ func openSaveMenu() {
 
 	// print button (and exit app)
 	form.AddButton("Print to stdout", func() {
+		recordInput()
 		exitTui()
 		json, _ := serializeTransformation()
 		fmt.Print(string(json))
@@ -271,6 +330,33 @@ func openSaveMenu() {
 	setInstructionsText(getInstructionString([]string{"app", "floating"}))
 }
 
+const commandErrorMenuPageName = "commandErrorMenu"
+func openCommandErrorMenu(err *commandError) {
+	form := tview.NewForm()
+	form.SetTitle("Command Failed").SetBorder(true)
+
+	doneFunc := func()  {
+		pages.RemovePage(commandErrorMenuPageName)
+	}
+
+	writeToMessageBuffer(err.Error())
+
+	// details
+	form.AddTextView("Command", err.command, 0, 2, false, false)
+	form.AddTextView("Exit status", fmt.Sprint(err.exitCode), 0, 1, false, false)
+	stderr := err.stderr
+	if stderr == "" { stderr = "(nothing)" }
+	form.AddTextView("Stderr", stderr, 0, 20, false, true)
+
+	// close button
+	form.AddButton("Close", func() {
+		doneFunc()
+	})
+
+	createFloatingMenu(commandErrorMenuPageName, form, doneFunc)
+	setInstructionsText(getInstructionString([]string{"app", "floating"}))
+}
+
 const presetMenuPageName = "columnMenu"
 func openPresetMenu() {
 	list := tview.NewList()
@@ -319,7 +405,7 @@ func openPresetMenu() {
 		if activePresetIndex > 0 { list.SetItemText(activePresetIndex, oldPresetName, getPresetAltText(oldPresetName)) }
 		activePresetIndex = i
 
-		writeToMessageBuffer(fmt.Sprintf("Using preset: %v", presetName))
+		writeToMessageBuffer(strings.Join(append([]string{ fmt.Sprintf("Using preset: %v", presetName) }, getFilterErrors()...), "; "))
 	})
 
 	// x to remove preset
@@ -431,38 +517,97 @@ func openColumnFilterMenu() {
 		excludeRegex = excludeVal
 	}
 
+	compareFilter := transformation.CompareFilterByColumn[columnHeader]
+	includeOptions := transformation.IncludeOptionsByColumn[columnHeader]
+	excludeOptions := transformation.ExcludeOptionsByColumn[columnHeader]
+
+	// restores the filter from before the menu was opened
+	oldIncludeRegex, oldIncludeOptions, oldExcludeRegex, oldExcludeOptions, oldCompareFilter := includeRegex, includeOptions, excludeRegex, excludeOptions, compareFilter
+	restoreFilter := func() {
+		setColumnFilter(columnHeader, oldIncludeRegex, oldIncludeOptions, oldExcludeRegex, oldExcludeOptions, oldCompareFilter)
+		refilterTuiTable()
+	}
+
+	// the table is filtered while typing, and the status shows how many entries match (or what's wrong)
+	statusView := tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	statusView.SetLabel("Status").SetSize(3, 0)
+	previewFilter := func() error {
+		setColumnFilter(columnHeader, includeRegex, includeOptions, excludeRegex, excludeOptions, compareFilter)
+		refilterTuiTable()
+
+		err := validateColumnFilter(columnHeader, includeRegex, includeOptions, excludeRegex, excludeOptions, compareFilter)
+		if err != nil {
+			statusView.SetText("[red]" + tview.Escape(err.Error()) + "[w]")
+		} else {
+			statusView.SetText(fmt.Sprintf("[green]%v of %v entries match[w]", len(outputEntryIndices), data.numEntries))
+		}
+		return err
+	}
+
 	// regex inputs
 	filterMenu := tview.NewForm() 
 	filterMenu.SetBorder(true).SetTitle("Filter Menu")
+	// adds the options of a pattern
+	addOptionFields := func(name string, options *FilterOptions) {
+		syntaxIndex := max(slices.Index(filterSyntaxes, options.Syntax), 0)
+		filterMenu.AddDropDown(name + " Match", filterSyntaxes, syntaxIndex, func(option string, index int) {
+			options.Syntax = option
+			if option == filterSyntaxes[0] { options.Syntax = "" }
+			previewFilter()
+		})
+		filterMenu.AddCheckbox(name + " Ignore Case", options.IgnoreCase, func(checked bool) {
+			options.IgnoreCase = checked
+			previewFilter()
+		})
+		filterMenu.AddCheckbox(name + " Whole Cell", options.WholeCell, func(checked bool) {
+			options.WholeCell = checked
+			previewFilter()
+		})
+	}
+
 	// include
-	filterMenu.AddInputField("Include Regex", includeRegex, 50, nil, func(text string) {
+	filterMenu.AddInputField("Include", includeRegex, 50, nil, func(text string) {
 		includeRegex = text
+		previewFilter()
 	})
+	addOptionFields("Include", &includeOptions)
 	// exclude
-	filterMenu.AddInputField("Exclude Regex", excludeRegex, 50, nil, func(text string) {
+	filterMenu.AddInputField("Exclude", excludeRegex, 50, nil, func(text string) {
 		excludeRegex = text
+		previewFilter()
 	})
+	addOptionFields("Exclude", &excludeOptions)
+	// comparison
+	const compareLabel = "Compare (> 10, 1h..2h, in (a, b))"
+	filterMenu.AddInputField(compareLabel, compareFilter, 50, nil, func(text string) {
+		compareFilter = text
+		previewFilter()
+	})
+	filterMenu.AddFormItem(statusView)
+	previewFilter()
 
-	// finish function
-	finishFunc := func() {
-
-		if includeRegex != "" {
-			transformation.IncludeRegexByColumn[columnHeader] = includeRegex
-		} else {
-			delete(transformation.IncludeRegexByColumn, columnHeader)
-		}
+	// suggest values from the column
+	values := getRankedValues(columnHeader)
+	setValueCompletion(filterMenu.GetFormItemByLabel("Include").(*tview.InputField), values, func(text string) *valueCompletion {
+		return getPatternCompletion(text, includeOptions)
+	})
+	setValueCompletion(filterMenu.GetFormItemByLabel("Exclude").(*tview.InputField), values, func(text string) *valueCompletion {
+		return getPatternCompletion(text, excludeOptions)
+	})
+	setValueCompletion(filterMenu.GetFormItemByLabel(compareLabel).(*tview.InputField), values, getComparisonCompletion)
 
-		if excludeRegex != "" {
-			transformation.ExcludeRegexByColumn[columnHeader] = excludeRegex
-		} else {
-			delete(transformation.ExcludeRegexByColumn, columnHeader)
+	// finish function (filters that don't compile aren't applied)
+	finishFunc := func() {
+		if err := previewFilter(); err != nil {
+			writeToMessageBuffer(fmt.Sprintf("Invalid filter: %v", err))
+			return
 		}
 
 		pages.RemovePage(filterMenuPageName)
-		refilterTuiTable()
 	}
 
 	cancelFunc := func() {
+		restoreFilter()
 		pages.RemovePage(filterMenuPageName)
 	}
 
@@ -474,6 +619,99 @@ func openColumnFilterMenu() {
 	setInstructionsText(getInstructionString([]string{"app", "floating"}))
 }
 
+// shows a dropdown of values while typing in a field, picking one adds it to the field's text
+func setValueCompletion(field *tview.InputField, values []string, getCompletion func(text string) *valueCompletion) {
+	var completion *valueCompletion
+	var completions []string
+
+	field.SetAutocompleteFunc(func(text string) []string {
+		completion = getCompletion(text)
+		if completion == nil { return nil }
+
+		// (values are escaped so they are shown as they are)
+		completions = getValueCompletions(values, completion)
+		entries := make([]string, len(completions))
+		for i, value := range completions { entries[i] = tview.Escape(value) }
+		return entries
+	})
+	field.SetAutocompletedFunc(func(text string, index int, source int) bool {
+		if source == tview.AutocompletedNavigate || completion == nil { return false }
+
+		field.SetText(completion.complete(completions[index]))
+		return true
+	})
+}
+
+const expressionMenuPageName = "expressionMenu"
+func openExpressionFilterMenu() {
+	expression := transformation.FilterExpression
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Row Filter Menu")
+
+	// shows whether the expression parses (and what's wrong if it doesn't)
+	statusView := tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	statusView.SetLabel("Status").SetSize(3, 0)
+	oldExpression := transformation.FilterExpression
+
+	previewExpression := func() error {
+		transformation.FilterExpression = strings.TrimSpace(expression)
+		refilterTuiTable()
+
+		var err error
+		if transformation.FilterExpression != "" {
+			var parsed *expressionNode
+			parsed, err = parseFilterExpression(expression)
+			if err == nil {
+				if unknown := getUnknownExpressionColumns(parsed); len(unknown) > 0 {
+					err = fmt.Errorf("unknown column %q", unknown[0])
+				} else {
+					err = validateExpressionOperands(parsed)
+				}
+			}
+		}
+		if err != nil {
+			statusView.SetText("[red]" + tview.Escape(err.Error()) + "[w]")
+		} else {
+			statusView.SetText(fmt.Sprintf("[green]%v of %v entries match[w]", len(outputEntryIndices), data.numEntries))
+		}
+		return err
+	}
+
+	form.AddInputField("Expression", expression, 60, nil, func(text string) {
+		expression = text
+		previewExpression()
+	})
+	form.AddFormItem(statusView)
+	form.AddTextView("Examples", "status == Error or restarts > 5\nnamespace = prod and not name ~ ^canary\nage < 1h and phase in (Pending, Failed)", 0, 3, false, false)
+	previewExpression()
+
+	finishFunc := func() {
+		if err := previewExpression(); err != nil {
+			writeToMessageBuffer(fmt.Sprintf("Invalid filter expression: %v", err))
+			return
+		}
+
+		pages.RemovePage(expressionMenuPageName)
+	}
+
+	cancelFunc := func() {
+		transformation.FilterExpression = oldExpression
+		refilterTuiTable()
+		pages.RemovePage(expressionMenuPageName)
+	}
+
+	form.AddButton("Done", finishFunc)
+	form.AddButton("Clear", func() {
+		expression = ""
+		finishFunc()
+	})
+	form.AddButton("Cancel", cancelFunc)
+
+	createFloatingMenu(expressionMenuPageName, form, cancelFunc)
+	setInstructionsText(getInstructionString([]string{"app", "floating"}))
+}
+
 func deleteSelectedColumn() {
 	if !confirmValidColumnSelection() { return }
 
@@ -482,15 +720,75 @@ func deleteSelectedColumn() {
 	refilterTuiTable()
 }
 
+// cycles the selected column through the types (starting from the inferred type)
+func cycleColumnType() {
+	if !confirmValidColumnSelection() { return }
+
+	header := transformation.ColumnHeaders[selC]
+	if transformation.ColumnTypes == nil { transformation.ColumnTypes = make(map[string]string) }
+	columnType, overridden := transformation.ColumnTypes[header]
+	if typeIndex := slices.Index(columnTypes, columnType); !overridden || typeIndex == -1 {
+		transformation.ColumnTypes[header] = columnTypes[0]
+	} else if typeIndex == len(columnTypes) - 1 {
+		delete(transformation.ColumnTypes, header)
+	} else {
+		transformation.ColumnTypes[header] = columnTypes[typeIndex + 1]
+	}
+
+	if _, overridden := transformation.ColumnTypes[header]; overridden {
+		writeToMessageBuffer(fmt.Sprintf("Column type: %v", getColumnType(header)))
+	} else {
+		writeToMessageBuffer(fmt.Sprintf("Column type: %v (inferred)", getColumnType(header)))
+	}
+	refilterTuiTable()
+}
+
+// cycles the order the selected column is sorted in
+func cycleSortMode() {
+	if !confirmValidColumnSelection() { return }
+
+	sortKeyIndex := getSortKeyIndex(transformation.ColumnHeaders[selC])
+	if sortKeyIndex == -1 {
+		writeToMessageBuffer("Column is not sorted")
+		return
+	}
+	sortKey := &transformation.SortKeys[sortKeyIndex]
+
+	modeIndex := (max(slices.Index(sortModes, sortKey.Mode), 0) + 1) % len(sortModes)
+	sortKey.Mode = sortModes[modeIndex]
+	writeToMessageBuffer(fmt.Sprintf("Sort mode: %v", sortModes[modeIndex]))
+	if modeIndex == 0 { sortKey.Mode = "" } // (the default isn't saved)
+
+	refilterTuiTable()
+}
+
+// sorts by the selected column only (or flips it, if it already is the only sort)
 func sortColumn() {
 	if !confirmValidColumnSelection() { return }
 
 	// update sort config
 	newColumn := transformation.ColumnHeaders[selC]
-	if newColumn != transformation.SortByColumn {
-		transformation.SortByColumn = newColumn
+	if len(transformation.SortKeys) == 1 && transformation.SortKeys[0].Column == newColumn {
+		transformation.SortKeys[0].Ascending = !transformation.SortKeys[0].Ascending
 	} else {
-		transformation.SortAscending = !transformation.SortAscending
+		transformation.SortKeys = []SortKey{ { newColumn, true, "" } }
+	}
+
+	refilterTuiTable()
+}
+
+// adds the selected column as the last sort (then flips it, then removes it)
+func addSortColumn() {
+	if !confirmValidColumnSelection() { return }
+
+	newColumn := transformation.ColumnHeaders[selC]
+	switch sortKeyIndex := getSortKeyIndex(newColumn); {
+	case sortKeyIndex == -1:
+		transformation.SortKeys = append(transformation.SortKeys, SortKey{ newColumn, true, "" })
+	case transformation.SortKeys[sortKeyIndex].Ascending:
+		transformation.SortKeys[sortKeyIndex].Ascending = false
+	default:
+		transformation.SortKeys = slices.Delete(transformation.SortKeys, sortKeyIndex, sortKeyIndex + 1)
 	}
 
 	refilterTuiTable()
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"math"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// column types, in the order they are tried when inferring (the first one every value fits is used)
+var columnTypes = []string{ "int", "float", "size", "duration", "time", "ip", "version", "text" }
+
+// how many entries of a column are looked at to infer its type
+const typeSampleSize = 1000
+
+// values that mean a cell is missing, they fit every type
+var missingValues = []string{ "", noDataText, "-", "<none>", "<unknown>", "n/a" }
+
+// a value parsed by its column's type, compared part by part (nil when it doesn't parse)
+// numbers have one part, ips have their bytes and port, and versions have their numbers
+type typedValue []float64
+
+// values of a column parsed by its type (extended as entries are added)
+type typedColumn struct {
+	columnType string
+	values []typedValue
+}
+
+// gets a column's type (set in the transformation, or inferred from its entries)
+func getColumnType(header string) string {
+	if columnType, ok := transformation.ColumnTypes[header]; ok && slices.Contains(columnTypes, columnType) {
+		return columnType
+	}
+
+	columnType, ok := data.columnTypes[header]
+	if !ok {
+		columnType = inferColumnType(data.entriesByColumn[header])
+		if data.columnTypes == nil { data.columnTypes = make(map[string]string) }
+		data.columnTypes[header] = columnType
+	}
+	return columnType
+}
+
+func inferColumnType(entries []string) string {
+	sample := entries[:min(typeSampleSize, len(entries))]
+	sample = slices.DeleteFunc(slices.Clone(sample), isMissingValue)
+	if len(sample) == 0 { return "text" }
+
+	for _, columnType := range columnTypes {
+		fits := true
+		for _, entry := range sample {
+			if parseTypedValue(entry, columnType) == nil {
+				fits = false
+				break
+			}
+		}
+
+		// sizes need a unit somewhere (or they are just numbers), and one that isn't also a duration (5m is minutes, not megabytes)
+		if fits && columnType == "size" && !slices.ContainsFunc(sample, func(entry string) bool {
+			return strings.ContainsAny(entry, "KMGTPEkmgtpeBb") && parseDuration(entry) == nil
+		}) {
+			fits = false
+		}
+		if fits { return columnType }
+	}
+	return "text"
+}
+
+func isMissingValue(value string) bool {
+	return slices.Contains(missingValues, strings.ToLower(strings.TrimSpace(value)))
+}
+
+// gets the parsed values of a column
+func getTypedColumn(header string) []typedValue {
+	columnType := getColumnType(header)
+	if data.typedColumns == nil { data.typedColumns = make(map[string]*typedColumn) }
+
+	column, ok := data.typedColumns[header]
+	if !ok || column.columnType != columnType {
+		column = &typedColumn{ columnType: columnType }
+		data.typedColumns[header] = column
+	}
+
+	entries := data.entriesByColumn[header]
+	for len(column.values) < len(entries) {
+		column.values = append(column.values, parseTypedValue(entries[len(column.values)], columnType))
+	}
+	return column.values
+}
+
+// compares parsed values (values that parsed go before values that didn't)
+func compareTypedValues(a typedValue, b typedValue) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] < b[i] {
+			return -1
+		} else if a[i] > b[i] {
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// PARSING ===================================================================================
+
+var sizeRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGTPEkmgtpe]?)(i?)[Bb]?$`)
+var durationRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h|d|w|y))+$`)
+var durationPartRegex = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w|y)`)
+var clockDurationRegex = regexp.MustCompile(`^(?:(\d+)-)?(\d+):(\d{2})(?::(\d{2}(?:\.\d+)?))?$`)
+var versionRegex = regexp.MustCompile(`^[vV]?(\d+(?:\.\d+)+)(?:[-+~](.*))?$`)
+
+// size units, and seconds in each duration unit
+const sizeUnits = "KMGTPE"
+var durationUnitSeconds = map[string]float64{
+	"ns": 1e-9, "us": 1e-6, "µs": 1e-6, "ms": 1e-3, "s": 1, "m": 60, "h": 3600, "d": 86400, "w": 604800, "y": 31536000,
+}
+
+// timestamps formats that are tried in order
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.UnixDate,
+	time.ANSIC,
+	time.Stamp,
+}
+
+// parses a value as a type, missing values and text are nil
+func parseTypedValue(value string, columnType string) typedValue {
+	value = strings.TrimSpace(value)
+	if isMissingValue(value) { return nil }
+
+	switch columnType {
+	case "int":
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return typedValue{ float64(number) }
+		}
+	case "float":
+		number, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+			return typedValue{ number }
+		}
+	case "size":
+		return parseSize(value)
+	case "duration":
+		return parseDuration(value)
+	case "time":
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return typedValue{ float64(t.UnixNano()) / 1e9 }
+			}
+		}
+	case "ip":
+		return parseIP(value)
+	case "version":
+		return parseVersion(value)
+	}
+	return nil
+}
+
+// parses sizes like 1.5Gi, 200M or 12kB into bytes (units with an i are powers of 1024)
+func parseSize(value string) typedValue {
+	match := sizeRegex.FindStringSubmatch(value)
+	if match == nil { return nil }
+
+	number, _ := strconv.ParseFloat(match[1], 64)
+	base := 1000.0
+	if match[3] != "" { base = 1024 }
+	if match[2] != "" {
+		number *= math.Pow(base, float64(strings.Index(sizeUnits, strings.ToUpper(match[2])) + 1))
+	}
+	return typedValue{ number }
+}
+
+// parses durations like 3d4h, 1m30s or 01:02:03 (ps style) into seconds
+func parseDuration(value string) typedValue {
+	if value == "0" { return typedValue{ 0 } }
+
+	if durationRegex.MatchString(value) {
+		seconds := 0.0
+		for _, part := range durationPartRegex.FindAllStringSubmatch(value, -1) {
+			number, _ := strconv.ParseFloat(part[1], 64)
+			seconds += number * durationUnitSeconds[part[2]]
+		}
+		return typedValue{ seconds }
+	}
+
+	// [days-]hours:minutes[:seconds] or minutes:seconds
+	if match := clockDurationRegex.FindStringSubmatch(value); match != nil {
+		days, _ := strconv.ParseFloat(match[1], 64)
+		first, _ := strconv.ParseFloat(match[2], 64)
+		second, _ := strconv.ParseFloat(match[3], 64)
+		if match[4] == "" {
+			return typedValue{ days * 86400 + first * 60 + second }
+		}
+		third, _ := strconv.ParseFloat(match[4], 64)
+		return typedValue{ days * 86400 + first * 3600 + second * 60 + third }
+	}
+	return nil
+}
+
+// parses ip addresses, with a port or prefix length (ipv4 is sorted with ipv6 as mapped addresses)
+func parseIP(value string) typedValue {
+	var address netip.Addr
+	suffix := 0.0
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		address, suffix = prefix.Addr(), float64(prefix.Bits())
+	} else if addressPort, err := netip.ParseAddrPort(value); err == nil {
+		address, suffix = addressPort.Addr(), float64(addressPort.Port())
+	} else if address, err = netip.ParseAddr(value); err != nil {
+		return nil
+	}
+
+	var parsed typedValue
+	for _, b := range address.As16() {
+		parsed = append(parsed, float64(b))
+	}
+	return append(parsed, suffix)
+}
+
+// parses versions like v1.10.2 into their numbers (pre-releases like 1.0.0-rc1 go before the release)
+func parseVersion(value string) typedValue {
+	match := versionRegex.FindStringSubmatch(value)
+	if match == nil { return nil }
+
+	var parsed typedValue
+	for _, part := range strings.Split(match[1], ".") {
+		number, _ := strconv.ParseFloat(part, 64)
+		parsed = append(parsed, number)
+	}
+
+	// (the last part keeps releases after their pre-releases, even with fewer parts)
+	isRelease := 0.0
+	if match[2] == "" || strings.HasPrefix(value[len(value) - len(match[2]) - 1:], "+") { isRelease = 1 }
+	return append(parsed, math.Inf(-1), isRelease)
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestInferColumnType(t *testing.T) {
+	tests := []struct {
+		entries []string
+		columnType string
+	}{
+		{ []string{ "1", "20", "-" }, "int" },
+		{ []string{ "1.5", "2" }, "float" },
+		{ []string{ "5m", "12m", "45m" }, "duration" },
+		{ []string{ "5m", "12M" }, "size" },
+		{ []string{ "1.5Gi", "200Mi" }, "size" },
+		{ []string{ "3d4h", "10s" }, "duration" },
+		{ []string{ "v1.2.0", "1.10.1" }, "version" },
+		{ []string{ "10.0.0.1", "::1" }, "ip" },
+		{ []string{ "Running", "Error" }, "text" },
+	}
+
+	for _, test := range tests {
+		if columnType := inferColumnType(test.entries); columnType != test.columnType {
+			t.Errorf("inferColumnType(%q) = %v, want %v", test.entries, columnType, test.columnType)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// the command is re-run (on an interval or with the refresh key), and its output replaces the input data.
+// the transformation stays, and the selection stays on the same entry (matched by the key column)
+
+// how long cells that changed in a refresh stay highlighted
+const changeHighlightDuration = 2 * time.Second
+
+// command that input came from, which can be re-run ("" when input came from files or stdin)
+var refreshableCommand string
+
+var refreshing atomic.Bool
+
+// cells that changed in the last refresh (by key and header), and when they stop being highlighted
+var changedCells map[string]map[string]bool
+var changeHighlightEnd time.Time
+
+func startWatching(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			refreshInput()
+		}
+	}()
+}
+
+// re-runs the command in the background, then swaps its output in
+func refreshInput() {
+	if !refreshing.CompareAndSwap(false, true) { return }
+
+	go func() {
+		output, err := io.ReadAll(getCommandOutput(refreshableCommand))
+		app.QueueUpdateDraw(func() {
+			defer refreshing.Store(false)
+
+			// keep the old data when the command fails (unless its output is wanted), or while the first load is still going
+			if data.loading { return }
+			if err != nil {
+				writeToMessageBuffer(fmt.Sprintf("Could not refresh: %v", err))
+				if !*flags.partialOutput { return }
+			}
+
+			replaceInputData(string(output))
+		})
+	}()
+}
+
+// gets the column that identifies entries across refreshes
+func getKeyColumn() string {
+	if *flags.keyColumn != "" || len(data.columnHeaders) == 0 {
+		return *flags.keyColumn
+	}
+	return data.columnHeaders[0]
+}
+
+// parses new input over the input data, keeping the selected entry and highlighting what changed
+func replaceInputData(output string) {
+	keyHeader := getKeyColumn()
+
+	// remember the selected entry, and the old entries by key
+	selectedKey, hasSelection := "", false
+	if selR >= data.numHeaderRows && selR - data.numHeaderRows < len(outputEntryIndices) {
+		selectedKey, hasSelection = getDataInColumn(keyHeader, outputEntryIndices[selR - data.numHeaderRows]), true
+	}
+	oldEntryByKey := getEntriesByKey(keyHeader)
+	oldEntriesByColumn, oldColumnHeaders := data.entriesByColumn, data.columnHeaders
+
+	// parse and transform the new input
+	if err := loadRefreshedInput(output); err != nil {
+		writeToMessageBuffer(fmt.Sprintf("Could not refresh: %v", err))
+		return
+	}
+	refilterTuiTable()
+
+	// find changed cells (new entries are changed entirely)
+	changedCells = make(map[string]map[string]bool)
+	for key, entry := range getEntriesByKey(keyHeader) {
+		oldEntry, existed := oldEntryByKey[key]
+		for _, header := range data.columnHeaders {
+			if existed && slices.Contains(oldColumnHeaders, header) && oldEntriesByColumn[header][oldEntry] == data.entriesByColumn[header][entry] { continue }
+
+			if changedCells[key] == nil { changedCells[key] = make(map[string]bool) }
+			changedCells[key][header] = true
+		}
+	}
+	changeHighlightEnd = time.Now().Add(changeHighlightDuration)
+	time.AfterFunc(changeHighlightDuration, func() {
+		app.QueueUpdateDraw(func() {
+			if !time.Now().Before(changeHighlightEnd) { changedCells = nil }
+		})
+	})
+
+	// select the same entry again
+	if hasSelection {
+		for i, entry := range outputEntryIndices {
+			if getDataInColumn(keyHeader, entry) == selectedKey {
+				selR = i + data.numHeaderRows
+				break
+			}
+		}
+	}
+	selR = min(selR, tableData.GetRowCount() - 1)
+	if selC >= len(transformation.ColumnHeaders) { selC = len(transformation.ColumnHeaders) - 1 }
+}
+
+// parses new input into the input data, or keeps the old data if it can't be parsed
+func loadRefreshedInput(output string) error {
+	// (loading replaces the data's maps and slices instead of changing them, so the old ones are left whole)
+	oldData, oldColumnHeaders := data, transformation.ColumnHeaders
+
+	err := loadInputs([]inputSource{ { refreshableCommand, strings.NewReader(output) } }, false)
+	if err != nil {
+		data, transformation.ColumnHeaders = oldData, oldColumnHeaders
+	}
+	return err
+}
+
+// maps each key to its entry (the first one, if keys repeat)
+func getEntriesByKey(keyHeader string) map[string]int {
+	entryByKey := make(map[string]int)
+	for entry := data.numEntries - 1; entry >= 0; entry-- {
+		entryByKey[getDataInColumn(keyHeader, entry)] = entry
+	}
+	return entryByKey
+}
+
+func isCellChanged(header string, entry int) bool {
+	return changedCells[getDataInColumn(getKeyColumn(), entry)][header]
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadRefreshedInputKeepsDataOnError(t *testing.T) {
+	transformation.HeaderRegex = "^name"
+	defer func() { transformation.HeaderRegex = "" }()
+
+	if err := loadRefreshedInput("name status\na Running\nb Error\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// (the header regex doesn't match the refreshed output)
+	if err := loadRefreshedInput("error: the server is unreachable\n"); err == nil {
+		t.Error("output without a header was accepted")
+	}
+	if !slices.Equal(data.columnHeaders, []string{ "name", "status" }) {
+		t.Errorf("headers = %q, want [name status]", data.columnHeaders)
+	}
+	if entries := data.entriesByColumn["status"]; data.numEntries != 2 || !slices.Equal(entries, []string{ "Running", "Error" }) {
+		t.Errorf("status entries = %q (%v entries), want [Running Error]", entries, data.numEntries)
+	}
+	if data.loading {
+		t.Error("data is still loading")
+	}
+}